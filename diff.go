@@ -0,0 +1,160 @@
+package gmodel
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/xiaoqicheng/gmodel/color"
+	"github.com/xiaoqicheng/gmodel/parser"
+	"sort"
+	"strings"
+)
+
+// newDiffCmd 获取 gmodel diff cmd, 对比已有 model 文件与数据库表结构, 存在差异时退出码为 1
+func (conf *GModelsConf) newDiffCmd() *cobra.Command {
+	modelArgs := &ModelOptions{}
+	var diffCmd = &cobra.Command{
+		Use:          "diff",
+		Short:        "compare the models with the database schema",
+		Example:      "gmodel diff --slm default -o ./dao/internal",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := conf.secondInitFlags(modelArgs); err != nil {
+				return err
+			}
+			conf.configOnlyOptions(cmd, modelArgs)
+			return diffModel(modelArgs)
+		},
+	}
+
+	conf.initDiffFlags(diffCmd, modelArgs)
+
+	return diffCmd
+}
+
+// initDiffFlags diff 使用与生成相同的连接及类型参数, 保证 go 类型的比较结果一致
+func (conf *GModelsConf) initDiffFlags(diffCmd *cobra.Command, modelArgs *ModelOptions) {
+	diffCmd.Flags().StringVar(&modelArgs.SelectMySQL, "slm", conf.DefaultMysql, "select connection")
+
+	defaultMysqlConf := conf.confOption[conf.DefaultMysql]
+
+	diffCmd.Flags().StringVarP(&modelArgs.OutputPath, "output", "o", defaultMysqlConf.OutputPath, "model path")
+	diffCmd.Flags().StringVarP(&modelArgs.MysqlDsn, "db-dsn", "d", defaultMysqlConf.MysqlDsn, "mysql dsn([user]:[pass]@tcp(host)/[database][?charset=xxx&...])")
+	diffCmd.Flags().StringVar(&modelArgs.Driver, "driver", defaultMysqlConf.Driver, "database driver: mysql, postgres or sqlite, detected from dsn if empty")
+	diffCmd.Flags().StringVarP(&modelArgs.MysqlTable, "db-table", "t", defaultMysqlConf.MysqlTable, "mysql table name, or comma separated names, globs (user_*) and /regexp/")
+	diffCmd.Flags().StringSliceVar(&modelArgs.ExcludeTables, "exclude", defaultMysqlConf.ExcludeTables, "skip tables by comma separated names, globs (tmp_*) and /regexp/")
+	diffCmd.Flags().BoolVar(&modelArgs.Views, "views", defaultMysqlConf.Views, "also compare the models of the views")
+	diffCmd.Flags().StringVar(&modelArgs.TablePrefix, "table-prefix", defaultMysqlConf.TablePrefix, "table name prefix")
+	diffCmd.Flags().StringVar(&modelArgs.ColumnPrefix, "col-prefix", "", "column name prefix")
+	diffCmd.Flags().BoolVar(&modelArgs.NoNullType, "no-null", false, "do not use Null type")
+	diffCmd.Flags().StringVar(&modelArgs.NullStyle, "null-style", "",
+		"null type: sql.NullXXX(use 'sql') or *xxx(use 'ptr')")
+	diffCmd.Flags().BoolVar(&modelArgs.JudgeUnsigned, "unsigned", false, "Whether to determine an unsigned type")
+}
+
+// diffModel 读取 model 目录中的 struct, 与数据库中的表逐个比较
+func diffModel(modelArgs *ModelOptions) error {
+	if err := judgeMysqlDsnIsNull(modelArgs); err != nil {
+		return err
+	}
+	if flavor, _ := parser.ParseFlavor(modelArgs.Flavor); flavor != parser.FlavorGorm {
+		return fmt.Errorf("%s reads the gorm tags of the models, flavor %s is not supported", "diff", modelArgs.Flavor)
+	}
+
+	driver, err := parser.ParseDriver(modelArgs.Driver, modelArgs.MysqlDsn)
+	if err != nil {
+		return err
+	}
+
+	modelDir := defaultString(modelArgs.OutputPath, "./")
+	models, err := parser.ParseModelDir(modelDir)
+	if err != nil {
+		return fmt.Errorf("parse models error: %w", err)
+	}
+	modelByTable := make(map[string]*parser.ModelStruct, len(models))
+	for _, model := range models {
+		modelByTable[model.Table.Name] = model
+	}
+
+	in, err := parser.NewIntrospector(driver, modelArgs.MysqlDsn, modelArgs.Jobs)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	in.IncludeViews(modelArgs.Views)
+
+	filter, err := tableFilter(modelArgs)
+	if err != nil {
+		return err
+	}
+	names, err := in.FilterTables(filter)
+	if err != nil {
+		return fmt.Errorf("get tables error: %w", err)
+	}
+	sort.Strings(names)
+
+	opt, err := getOptions(*modelArgs)
+	if err != nil {
+		return err
+	}
+	// 枚举类型名与生成时一样按全部表名避让
+	all, err := in.TableNames()
+	if err != nil {
+		return fmt.Errorf("get tables error: %w", err)
+	}
+	opt = append(opt, parser.WithTableNames(all...))
+	drift := false
+	for _, name := range names {
+		model, ok := modelByTable[name]
+		if !ok {
+			drift = true
+			fmt.Println(color.Green("+ table " + name))
+			continue
+		}
+		delete(modelByTable, name)
+
+		table, err := in.Table(name, opt...)
+		if err != nil {
+			return fmt.Errorf("get create table error: %w", err)
+		}
+		lines := parser.DiffModel(model, table, opt...)
+		if len(lines) == 0 {
+			continue
+		}
+		drift = true
+		fmt.Println(color.Yellow("~ table " + name + " (" + model.File + ")"))
+		for _, line := range lines {
+			fmt.Println("    " + diffColor(line))
+		}
+	}
+
+	// -t / --exclude 只比较选中的表, 未开启 --views 时跳过视图的 model
+	tables := make([]string, 0, len(modelByTable))
+	for name, model := range modelByTable {
+		if filter.Match(name) && (!model.View || modelArgs.Views) {
+			tables = append(tables, name)
+		}
+	}
+	sort.Strings(tables)
+	for _, name := range tables {
+		drift = true
+		fmt.Println(color.Red("- table " + name + " (" + modelByTable[name].File + ")"))
+	}
+
+	if drift {
+		return fmt.Errorf("models in %s have drifted from the database schema", modelDir)
+	}
+	fmt.Printf("%s \n", color.Blue(`no drift`))
+	return nil
+}
+
+// diffColor + 绿色, - 红色, ~ 黄色
+func diffColor(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return color.Green(line)
+	case strings.HasPrefix(line, "-"):
+		return color.Red(line)
+	default:
+		return color.Yellow(line)
+	}
+}
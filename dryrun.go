@@ -0,0 +1,194 @@
+package gmodel
+
+import (
+	"fmt"
+	"github.com/xiaoqicheng/gmodel/color"
+	"sort"
+	"strings"
+)
+
+// diffContext unified diff 中变更前后保留的行数
+const diffContext = 3
+
+// report 按路径顺序输出 dry run 的 diff 及统计, 跳过的文件计为未修改
+func (g *Generator) report(result Result) {
+	var files []FileResult
+	for _, table := range result.Tables {
+		files = append(files, table.Files...)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	var created, changed, unchanged int
+	for _, file := range files {
+		switch file.Status {
+		case FileCreated:
+			created++
+		case FileChanged:
+			changed++
+		default:
+			unchanged++
+			continue
+		}
+		fmt.Fprint(g.out, colorDiff(unifiedDiff(file.Path, file.Old, file.Code, file.Status != FileCreated)))
+	}
+
+	fmt.Fprintf(g.out, "%s %d created, %d changed, %d unchanged\n", color.Blue("dry run:"), created, changed, unchanged)
+}
+
+// colorDiff 新增行绿色, 删除行红色, hunk 头青色
+func colorDiff(diff string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "+++"), strings.HasPrefix(text, "---"):
+			b.WriteString(text)
+		case strings.HasPrefix(text, "@@"):
+			b.WriteString(color.Cyan(text))
+		case strings.HasPrefix(text, "+"):
+			b.WriteString(color.Green(text))
+		case strings.HasPrefix(text, "-"):
+			b.WriteString(color.Red(text))
+		default:
+			b.WriteString(text)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// diffOp 行级编辑操作: ' ' 相同, '-' 删除, '+' 新增
+type diffOp struct {
+	Kind byte
+	Text string
+}
+
+// unifiedDiff 生成 old 到 new 的 unified diff, 文件不存在时旧文件为 /dev/null
+func unifiedDiff(path string, old, new []byte, exists bool) string {
+	ops := diffLines(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	if exists {
+		fmt.Fprintf(&b, "--- a/%s\n", path)
+	} else {
+		b.WriteString("--- /dev/null\n")
+	}
+	fmt.Fprintf(&b, "+++ b/%s\n", path)
+
+	for start := 0; start < len(ops); {
+		// 跳过与上一个 hunk 无关的相同行
+		if ops[start].Kind == ' ' {
+			start++
+			continue
+		}
+		from := start - diffContext
+		if from < 0 {
+			from = 0
+		}
+		// 相邻变更间隔不超过 2*diffContext 行时合并为一个 hunk
+		end, same := start, 0
+		for ; end < len(ops) && same <= 2*diffContext; end++ {
+			if ops[end].Kind == ' ' {
+				same++
+			} else {
+				same = 0
+			}
+		}
+		end -= same
+		if end += diffContext; end > len(ops) {
+			end = len(ops)
+		}
+		writeHunk(&b, ops, from, end)
+		start = end
+	}
+	return b.String()
+}
+
+// writeHunk 写入 ops[from:end], 行号从 1 开始
+func writeHunk(b *strings.Builder, ops []diffOp, from, end int) {
+	oldLine, newLine := 1, 1
+	for _, op := range ops[:from] {
+		if op.Kind != '+' {
+			oldLine++
+		}
+		if op.Kind != '-' {
+			newLine++
+		}
+	}
+	var oldCount, newCount int
+	for _, op := range ops[from:end] {
+		if op.Kind != '+' {
+			oldCount++
+		}
+		if op.Kind != '-' {
+			newCount++
+		}
+	}
+	// 一侧为空时按 diff 的约定, 起始行号为前一行
+	if oldCount == 0 {
+		oldLine--
+	}
+	if newCount == 0 {
+		newLine--
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+	for _, op := range ops[from:end] {
+		b.WriteByte(op.Kind)
+		b.WriteString(op.Text)
+		b.WriteString("\n")
+	}
+}
+
+// diffLines 最长公共子序列求行级差异, model 文件不大, O(n*m) 足够
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// splitLines 按行切分, 忽略结尾换行
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
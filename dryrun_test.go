@@ -0,0 +1,44 @@
+package gmodel
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "package model\n\ntype Users struct {\n\tID int\n\tName string\n}\n\nfunc a() {}\nfunc b() {}\nfunc c() {}\nfunc d() {}\nfunc e() {}\nfunc f() {}\nfunc g() {}\n"
+	new := "package model\n\ntype Users struct {\n\tID int64\n\tName string\n}\n\nfunc a() {}\nfunc b() {}\nfunc c() {}\nfunc d() {}\nfunc e() {}\nfunc f() {}\nfunc g() {}\nfunc h() {}\n"
+	want := `--- a/model/users.go
++++ b/model/users.go
+@@ -1,7 +1,7 @@
+ package model
+ 
+ type Users struct {
+-	ID int
++	ID int64
+ 	Name string
+ }
+ 
+@@ -12,3 +12,4 @@
+ func e() {}
+ func f() {}
+ func g() {}
++func h() {}
+`
+	if got := unifiedDiff("model/users.go", []byte(old), []byte(new), true); got != want {
+		t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	want = `--- /dev/null
++++ b/model/users.go
+@@ -0,0 +1,2 @@
++package model
++
+`
+	if got := unifiedDiff("model/users.go", nil, []byte("package model\n\n"), false); got != want {
+		t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := unifiedDiff("model/users.go", []byte(old), []byte(old), true); got != "--- a/model/users.go\n+++ b/model/users.go\n" {
+		t.Errorf("unifiedDiff() of equal files = %q", got)
+	}
+}
@@ -1,49 +1,399 @@
 package gmodel
 
 import (
-	"flag"
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/xiaoqicheng/gmodel/color"
 	"github.com/xiaoqicheng/gmodel/parser"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 )
 
-// GenerateModel .
-func generateModel(defaultSelectModel string) {
+// FileStatus 生成的文件相对磁盘上原文件的状态
+type FileStatus string
 
-	//选择非默认连接信息 进行二次赋值
-	if err := secondInitFlags(&modelArgs, defaultSelectModel); err != nil {
-		return
+const (
+	// FileCreated .
+	FileCreated FileStatus = "created"
+
+	// FileChanged .
+	FileChanged FileStatus = "changed"
+
+	// FileUnchanged .
+	FileUnchanged FileStatus = "unchanged"
+
+	// FileSkipped 文件已存在且未指定 -u
+	FileSkipped FileStatus = "skipped"
+)
+
+const (
+	// FormatGo gorm model, 默认格式
+	FormatGo = "go"
+
+	// FormatProto 每个表一个 .proto 文件, 字段编号在多次生成间保持不变
+	FormatProto = "proto"
+
+	// FormatJSONSchema 每个表一个 JSON Schema 文件, 如 users.schema.json
+	FormatJSONSchema = "jsonschema"
+
+	// FormatOpenAPI 每个表一个 OpenAPI components.schemas 的 yaml 文件, 如 users.yaml
+	FormatOpenAPI = "openapi"
+
+	// FormatTypeScript 每个表一个 TypeScript interface 文件, 如 users.ts
+	FormatTypeScript = "ts"
+)
+
+// FileResult 一个生成的文件, dry run 时 Code 为将会写入的内容
+type FileResult struct {
+	Path   string
+	Status FileStatus
+	Old    []byte // 生成前磁盘上的内容, 文件不存在时为 nil
+	Code   []byte
+}
+
+// TableResult 单个表的生成结果, Files 依次为 model 文件和 dao 文件
+type TableResult struct {
+	Table string
+	Files []FileResult
+	Err   error
+}
+
+// Result .
+type Result struct {
+	Tables []TableResult
+}
+
+// Changed 是否有文件被 (dry run 时为将会被) 创建或修改
+func (r Result) Changed() bool {
+	for _, table := range r.Tables {
+		for _, file := range table.Files {
+			if file.Status == FileCreated || file.Status == FileChanged {
+				return true
+			}
+		}
 	}
+	return false
+}
 
-	judgeUpdateArgs()
-	//判断mysql连接是否为空参数
-	judgeMysqlDsnIsNull()
-	//sql 获取顺序为： -s > -f > "自动获取"
-	judgeMysqlSqlWithTable()
+// TableError 单个表生成失败的原因
+type TableError struct {
+	Table string
+	Err   error
+}
 
-	// 获取即将生成的表结构的所有表
-	tables, err := parser.GetCreateTables(modelArgs.MysqlDsn, modelArgs.MysqlTable)
+// Error .
+func (e *TableError) Error() string {
+	return fmt.Sprintf("table %s: %s", e.Table, e.Err)
+}
+
+// Unwrap .
+func (e *TableError) Unwrap() error {
+	return e.Err
+}
+
+// GenerateError 汇总生成失败的表, 单个表失败时其余表照常生成
+type GenerateError struct {
+	Total  int
+	Errors []*TableError
+}
+
+// Error .
+func (e *GenerateError) Error() string {
+	return fmt.Sprintf("%d of %d tables failed", len(e.Errors), e.Total)
+}
+
+// Generator 根据 ModelOptions 生成 model, 不依赖 cobra 及包级状态, 多个 Generator 可在同一进程中同时使用
+type Generator struct {
+	opts   ModelOptions
+	out    io.Writer
+	filter *parser.TableFilter // -t 与 exclude_tables
+	// tableNames 数据库或 sql 中的全部表名, 不受 -t 与 exclude_tables 影响
+	tableNames []string
+}
+
+// NewGenerator .
+func NewGenerator(opts ModelOptions) *Generator {
+	return &Generator{opts: opts, out: os.Stdout}
+}
+
+// SetOutput 设置进度及 dry run diff 的输出, 默认 os.Stdout, 传入 io.Discard 不输出
+func (g *Generator) SetOutput(w io.Writer) {
+	g.out = w
+}
+
+// Generate 读取表结构并写入 model (及 dao) 文件, 返回每个表的生成结果;
+// 单个表失败不影响其他表, 失败的表汇总为 *GenerateError 返回; ctx 取消后未开始的表不再生成
+func (g *Generator) Generate(ctx context.Context) (Result, error) {
+	if err := g.prepare(); err != nil {
+		return Result{}, err
+	}
+
+	var tables, schema []*parser.Table
+	var err error
+	if g.opts.SQL != "" || g.opts.MigrationDir != "" {
+		// 指定 sql 或 migration 目录时离线生成， 不需要连接数据库
+		tables, schema, err = g.readTablesFromSQL()
+	} else {
+		tables, schema, err = g.readTablesFromDB(ctx)
+	}
 	if err != nil {
-		exitWithInfo("get tables error: %s", err)
+		return Result{}, err
 	}
 
 	// 已存在的表不在更新， 只新增不存在的表， 除非使用 更新命令
-	wg := &sync.WaitGroup{}
+	result, err := g.writeTables(ctx, tables, schema)
+	if g.opts.DryRun {
+		g.report(result)
+		// --check 时有文件会被修改则返回错误, 退出码为 1
+		if result.Changed() && g.opts.Check && err == nil {
+			err = fmt.Errorf("generated files are out of date")
+		}
+		return result, err
+	}
+	if err != nil {
+		return result, err
+	}
+	fmt.Fprintf(g.out, "%s \n", color.Blue(`success`))
+	return result, nil
+}
+
+// prepare 检查参数并补全默认值
+func (g *Generator) prepare() error {
+	if err := judgeUpdateArgs(&g.opts); err != nil {
+		return err
+	}
+	//sql 获取顺序为： -s > -f > "自动获取"
+	if err := judgeMysqlSqlWithTable(&g.opts); err != nil {
+		return err
+	}
+	if err := judgeFormatArgs(&g.opts); err != nil {
+		return err
+	}
+	if err := judgeFlavorArgs(&g.opts); err != nil {
+		return err
+	}
+	if err := judgeDaoArgs(&g.opts); err != nil {
+		return err
+	}
+	if err := judgeConvertArgs(&g.opts); err != nil {
+		return err
+	}
+	if g.opts.SQL == "" && g.opts.MigrationDir == "" {
+		//判断mysql连接是否为空参数
+		if err := judgeMysqlDsnIsNull(&g.opts); err != nil {
+			return err
+		}
+	}
+	g.opts.Associations = g.opts.Associations || g.opts.HasMany
+	g.opts.DryRun = g.opts.DryRun || g.opts.Check
+
+	filter, err := tableFilter(&g.opts)
+	if err != nil {
+		return err
+	}
+	g.filter = filter
+	return nil
+}
+
+// readTablesFromDB 从数据库读取表结构, 返回待生成的表及全部表
+func (g *Generator) readTablesFromDB(ctx context.Context) ([]*parser.Table, []*parser.Table, error) {
+	driver, err := parser.ParseDriver(g.opts.Driver, g.opts.MysqlDsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	opt, err := getOptions(g.opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 所有表共用一个连接池, 最多 --jobs 个表同时读取
+	in, err := parser.NewIntrospector(driver, g.opts.MysqlDsn, g.opts.Jobs)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer in.Close()
+	// 视图默认跳过, -t 直接指定视图名时仍然生成
+	in.IncludeViews(g.opts.Views)
+	// 枚举类型名按全部表名避让, -t 只选中部分表时生成的类型名不变
+	if g.tableNames, err = in.TableNames(); err != nil {
+		return nil, nil, fmt.Errorf("get tables error: %w", err)
+	}
+
+	// 获取即将生成的表结构的所有表, 生成关联字段时需要读取全部表; 排除的表不读取
+	readFilter := g.filter
+	if g.opts.Associations {
+		if readFilter, err = parser.NewTableFilter(nil, g.opts.ExcludeTables); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// 先读取全部表结构, 再统一生成; --bulk 时通过 information_schema 一次读取全部表
+	var tables []*parser.Table
+	if g.opts.Bulk {
+		var names []string
+		if name, ok := readFilter.Name(); ok {
+			names = []string{name}
+		}
+		if tables, err = in.BulkTables(ctx, names, opt...); err != nil {
+			return nil, nil, fmt.Errorf("get create table error: %w", err)
+		}
+		tables = filterTables(tables, readFilter)
+	} else {
+		names, err := in.FilterTables(readFilter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get tables error: %w", err)
+		}
+		if tables, err = in.ReadTables(ctx, names, opt...); err != nil {
+			return nil, nil, fmt.Errorf("get create table error: %w", err)
+		}
+	}
+
+	selected, err := g.selectTables(tables, "database")
+	return selected, tables, err
+}
+
+// readTablesFromSQL 按顺序回放 sql 或 migration 目录中的 DDL 语句， 每个表生成一个 model 文件
+func (g *Generator) readTablesFromSQL() ([]*parser.Table, []*parser.Table, error) {
+	opt, err := getOptions(g.opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	var tables []*parser.Table
+	if g.opts.MigrationDir != "" {
+		tables, err = parser.GetTablesFromMigrations(g.opts.MigrationDir, opt...)
+	} else {
+		tables, err = parser.GetTablesFromSQL(g.opts.SQL, opt...)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse sql error: %w", err)
+	}
+	if len(tables) == 0 {
+		return nil, nil, fmt.Errorf("no CREATE TABLE statement found in sql")
+	}
+
+	g.tableNames = make([]string, 0, len(tables))
 	for _, table := range tables {
-		wg.Add(1)
-		go writeModelFile(table, modelArgs.SQL, wg)
+		g.tableNames = append(g.tableNames, table.Name)
+	}
+
+	// 排除的表也不参与外键关联
+	parsed := tables
+	tables = make([]*parser.Table, 0, len(parsed))
+	for _, table := range parsed {
+		if !g.filter.Excluded(table.Name) {
+			tables = append(tables, table)
+		}
+	}
+
+	selected, err := g.selectTables(tables, "sql")
+	return selected, tables, err
+}
+
+// selectTables -t 指定的表, 可为逗号分隔的表名、glob 或 /regexp/, 去掉 exclude_tables 排除的表
+func (g *Generator) selectTables(tables []*parser.Table, source string) ([]*parser.Table, error) {
+	selected := filterTables(tables, g.filter)
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("table(%s) not found in %s", defaultString(g.opts.MysqlTable, "*"), source)
+	}
+	return selected, nil
+}
+
+// filterTables .
+func filterTables(tables []*parser.Table, filter *parser.TableFilter) []*parser.Table {
+	selected := make([]*parser.Table, 0, len(tables))
+	for _, table := range tables {
+		if filter.Match(table.Name) {
+			selected = append(selected, table)
+		}
 	}
+	return selected
+}
 
+// tableFilter 根据 -t 与 exclude_tables 选择表
+func tableFilter(args *ModelOptions) (*parser.TableFilter, error) {
+	return parser.NewTableFilter([]string{args.MysqlTable}, args.ExcludeTables)
+}
+
+// writeTables 每个表一个协程写入文件, schema 为全部表, 用于解析外键关联;
+// 单个表失败不影响其他表, 全部完成后汇总输出失败的表
+func (g *Generator) writeTables(ctx context.Context, tables, schema []*parser.Table) (Result, error) {
+	result := Result{Tables: make([]TableResult, len(tables))}
+	wg := &sync.WaitGroup{}
+	for i, table := range tables {
+		i, table := i, table
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := &result.Tables[i]
+			res.Table = table.Name
+			if res.Err = ctx.Err(); res.Err != nil {
+				return
+			}
+			res.Files, res.Err = g.writeTableFiles(table, schema)
+		}()
+	}
 	wg.Wait()
-	fmt.Printf("%s \n", color.Blue(`success`))
-	return
+
+	genErr := &GenerateError{Total: len(tables)}
+	for _, res := range result.Tables {
+		if res.Err != nil {
+			genErr.Errors = append(genErr.Errors, &TableError{Table: res.Table, Err: res.Err})
+		}
+	}
+	if len(genErr.Errors) == 0 {
+		return result, nil
+	}
+	for _, e := range genErr.Errors {
+		fmt.Fprintln(g.out, color.Red("生成错误 ["+e.Table+"] "+e.Err.Error()))
+	}
+	return result, genErr
+}
+
+// writeTableFiles 写入表的 model 文件, 开启 --dao / --convert 时同时写入 dao 及转换函数文件
+func (g *Generator) writeTableFiles(table *parser.Table, schema []*parser.Table) ([]FileResult, error) {
+	if g.opts.Format == FormatProto {
+		file, err := g.writeProtoFile(table, schema)
+		if err != nil {
+			return nil, err
+		}
+		return []FileResult{file}, nil
+	}
+	if g.opts.Format != FormatGo {
+		file, err := g.writeFormatFile(table, schema)
+		if err != nil {
+			return nil, err
+		}
+		return []FileResult{file}, nil
+	}
+
+	model, err := g.writeModelFile(table, schema)
+	if err != nil {
+		return nil, err
+	}
+	files := []FileResult{model}
+	if g.opts.Dao {
+		dao, err := g.writeDaoFile(table, schema)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, dao)
+	}
+	if g.opts.Convert != "" {
+		convert, err := g.writeConvertFile(table, schema)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, convert)
+	}
+	return files, nil
 }
 
 // getOptions .
-func getOptions(args ModelOptions) []parser.Option {
+func getOptions(args ModelOptions) ([]parser.Option, error) {
 	opt := make([]parser.Option, 0, 1)
 	if args.Charset != "" {
 		opt = append(opt, parser.WithCharset(args.Charset))
@@ -70,8 +420,7 @@ func getOptions(args ModelOptions) []parser.Option {
 		case "ptr":
 			opt = append(opt, parser.WithNullStyle(parser.NullInPointer))
 		default:
-			fmt.Printf("invalid null style: %s\n", args.NullStyle)
-			return nil
+			return nil, fmt.Errorf("invalid null style: %s", args.NullStyle)
 		}
 	}
 
@@ -88,88 +437,309 @@ func getOptions(args ModelOptions) []parser.Option {
 	if args.JudgeUnsigned {
 		opt = append(opt, parser.WithJudgeUnsigned())
 	}
-	return opt
+	if args.HasMany {
+		opt = append(opt, parser.WithHasMany())
+	} else if args.Associations {
+		opt = append(opt, parser.WithAssociations())
+	}
+	if len(args.TypeMapping) > 0 {
+		opt = append(opt, parser.WithTypeMapping(args.TypeMapping...))
+	}
+	if args.TemplateDir != "" {
+		// --template-dir 写错时报错, 不静默使用内置模板
+		if _, err := os.ReadDir(args.TemplateDir); err != nil {
+			return nil, fmt.Errorf("read template dir %s failed, %w", args.TemplateDir, err)
+		}
+		opt = append(opt, parser.WithTemplateDir(args.TemplateDir))
+	}
+	if args.StructTemplate != "" || args.FileTemplate != "" {
+		opt = append(opt, parser.WithTemplateFile(args.StructTemplate, args.FileTemplate))
+	}
+	if args.ProtoGoPackage != "" {
+		opt = append(opt, parser.WithProtoGoPackage(args.ProtoGoPackage))
+	}
+	if args.TSBigIntString {
+		opt = append(opt, parser.WithTSBigIntString())
+	}
+	flavor, err := parser.ParseFlavor(args.Flavor)
+	if err != nil {
+		return nil, err
+	}
+	opt = append(opt, parser.WithFlavor(flavor))
+	return opt, nil
 }
 
-// createModelFile 创建model file 在指定目录
-func createModelFile(tableName, tablePrefix, filePath string) (*os.File, bool) {
+// modelFilePath model 文件路径, 去掉表前缀
+func modelFilePath(tableName, tablePrefix, filePath string) string {
 	fileName := tableName
-	if modelArgs.TablePrefix != "" && strings.HasPrefix(fileName, tablePrefix) {
+	if tablePrefix != "" && strings.HasPrefix(fileName, tablePrefix) {
 		fileName = fileName[len(tablePrefix):]
 	}
+	return filePath + "/" + fileName + ".go"
+}
+
+// formatFilePath 非 go 格式的文件路径, 如 users.proto
+func formatFilePath(tableName, tablePrefix, filePath, ext string) string {
+	return strings.TrimSuffix(modelFilePath(tableName, tablePrefix, filePath), ".go") + ext
+}
 
-	fileAddress := filePath + "/" + fileName + ".go"
-	//判断 -update 参数 是否存在 是 则不判断; 否 则判断文件是否存在; -u -t 更新某个表model; -u -e 更新全部表model
-	if modelArgs.Update == false {
+// skipExistingFile 判断 -update 参数 是否存在 是 则不判断; 否 则判断文件是否存在; -u -t 更新某个表model; -u -e 更新全部表model;
+// --dry-run / --check 时已存在的文件也重新生成并对比, 过期的文件不会被当作未变化
+func (g *Generator) skipExistingFile(tableName, fileAddress string) bool {
+	if g.opts.Update == false && !g.opts.DryRun {
 		//如果文件存在 则 跳过
 		if ok, _ := pathExists(fileAddress); ok {
-			fmt.Println(color.Cyan("model已存在 [" + tableName + "]"))
-			return nil, ok
+			fmt.Fprintln(g.out, color.Cyan("model已存在 ["+tableName+"]"))
+			return true
 		}
 	}
+	return false
+}
+
+//writeModelFile 将 model 写入 文件
+func (g *Generator) writeModelFile(table *parser.Table, schema []*parser.Table) (file FileResult, err error) {
+	//确定输出目录
+	dirPath, err := g.initDirPath(g.opts.OutputPath)
+	if err != nil {
+		return file, fmt.Errorf("init dir path %s failed, %w", g.opts.OutputPath, err)
+	}
+
+	//如果文件已存在 则 跳过
+	fileAddress := modelFilePath(table.Name, g.opts.TablePrefix, dirPath)
+	if g.skipExistingFile(table.Name, fileAddress) {
+		return FileResult{Path: fileAddress, Status: FileSkipped}, nil
+	}
 
-	f, err := os.OpenFile(fileAddress, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	opt, err := getOptions(g.opts)
 	if err != nil {
-		exitWithInfo("open %s failed, %s\n", filePath, err)
+		return file, err
 	}
+	opt = append(opt, parser.WithSchema(schema), parser.WithTableNames(g.tableNames...))
 
-	return f, false
+	if !g.opts.DryRun {
+		fmt.Fprintln(g.out, color.Yellow("正在生成 ["+table.Name+"]"))
+	}
+	// 自定义模板执行出错时可能 panic, 转为该表的错误
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	buf := &bytes.Buffer{}
+	if err = parser.ParseTablesToWrite([]*parser.Table{table}, buf, opt...); err != nil {
+		return file, err
+	}
+	if file, err = g.writeGeneratedFile(fileAddress, buf.Bytes()); err != nil {
+		return file, err
+	}
+	if !g.opts.DryRun {
+		fmt.Fprintln(g.out, color.Green("生成完毕 ["+table.Name+"]"))
+	}
+	return file, nil
 }
 
-//writeModelFile 将 model 写入 文件
-func writeModelFile(table, sql string, wg *sync.WaitGroup) {
-	defer wg.Done()
-	//确定输出目录
-	dirPath, err := initDirPath(modelArgs.OutputPath)
+//writeDaoFile 将 dao 写入 dao 目录
+func (g *Generator) writeDaoFile(table *parser.Table, schema []*parser.Table) (FileResult, error) {
+	dirPath, err := g.initDirPath(g.opts.DaoPath)
+	if err != nil {
+		return FileResult{}, fmt.Errorf("init dir path %s failed, %w", g.opts.DaoPath, err)
+	}
+
+	//如果文件已存在 则 跳过
+	fileAddress := modelFilePath(table.Name, g.opts.TablePrefix, dirPath)
+	if g.skipExistingFile(table.Name, fileAddress) {
+		return FileResult{Path: fileAddress, Status: FileSkipped}, nil
+	}
+
+	opt, err := getOptions(g.opts)
+	if err != nil {
+		return FileResult{}, err
+	}
+	// 枚举类型名需避开全部表名 (含未选中的表) 的 struct 名
+	opt = append(opt, parser.WithSchema(schema), parser.WithTableNames(g.tableNames...))
+	opt = append(opt, parser.WithDaoPackage(g.opts.DaoPackage), parser.WithModelImport(g.opts.ModelImport))
+
+	buf := &bytes.Buffer{}
+	if err = parser.ParseDaoToWrite(table, buf, opt...); err != nil {
+		return FileResult{}, err
+	}
+	file, err := g.writeGeneratedFile(fileAddress, buf.Bytes())
 	if err != nil {
-		exitWithInfo("init dir path %s failed, %s\n", modelArgs.OutputPath, err)
+		return file, err
 	}
+	if !g.opts.DryRun {
+		fmt.Fprintln(g.out, color.Green("dao 生成完毕 ["+table.Name+"]"))
+	}
+	return file, nil
+}
 
-	f, ok := createModelFile(table, modelArgs.TablePrefix, dirPath)
-	defer f.Close()
+//writeConvertFile 将 model 与 pb / dto 之间的转换函数写入 convert 目录
+func (g *Generator) writeConvertFile(table *parser.Table, schema []*parser.Table) (FileResult, error) {
+	dirPath, err := g.initDirPath(g.opts.ConvertPath)
+	if err != nil {
+		return FileResult{}, fmt.Errorf("init dir path %s failed, %w", g.opts.ConvertPath, err)
+	}
 
 	//如果文件已存在 则 跳过
-	if ok {
-		return
+	fileAddress := modelFilePath(table.Name, g.opts.TablePrefix, dirPath)
+	if g.skipExistingFile(table.Name, fileAddress) {
+		return FileResult{Path: fileAddress, Status: FileSkipped}, nil
 	}
 
-	opt := getOptions(modelArgs)
-	if opt == nil {
-		return
+	opt, err := getOptions(g.opts)
+	if err != nil {
+		return FileResult{}, err
 	}
+	// 枚举类型名需避开全部表名 (含未选中的表) 的 struct 名
+	opt = append(opt, parser.WithSchema(schema), parser.WithTableNames(g.tableNames...))
+	opt = append(opt, parser.WithModelImport(g.opts.ModelImport),
+		parser.WithConvert(g.opts.Convert, g.opts.ConvertImport), parser.WithConvertPackage(g.opts.ConvertPackage))
 
-	fmt.Println(color.Yellow("正在生成 [" + table + "]"))
-	defer func() {
-		if err := recover(); err != nil {
-			fmt.Println(color.Red("生成错误 [" + table + "]" + fmt.Sprintf("%v", err)))
-			return
-		}
+	buf := &bytes.Buffer{}
+	if err = parser.ParseConvertToWrite(table, buf, opt...); err != nil {
+		return FileResult{}, err
+	}
+	file, err := g.writeGeneratedFile(fileAddress, buf.Bytes())
+	if err != nil {
+		return file, err
+	}
+	if !g.opts.DryRun {
+		fmt.Fprintln(g.out, color.Green("convert 生成完毕 ["+table.Name+"]"))
+	}
+	return file, nil
+}
 
-		fmt.Println(color.Green("生成完毕 [" + table + "]"))
-	}()
+//writeProtoFile 将表的 message 写入 .proto 文件, 字段编号沿用原文件中的编号
+func (g *Generator) writeProtoFile(table *parser.Table, schema []*parser.Table) (FileResult, error) {
+	dirPath, err := g.initDirPath(g.opts.OutputPath)
+	if err != nil {
+		return FileResult{}, fmt.Errorf("init dir path %s failed, %w", g.opts.OutputPath, err)
+	}
 
-	if sql == "" {
-		//自动获取 sql 参数
-		sql, err = parser.GetCreateTableFromDB(modelArgs.MysqlDsn, table)
-		if err != nil {
-			exitWithInfo("get create table error: %s", err)
-		}
+	//如果文件已存在 则 跳过
+	fileAddress := formatFilePath(table.Name, g.opts.TablePrefix, dirPath, ".proto")
+	if g.skipExistingFile(table.Name, fileAddress) {
+		return FileResult{Path: fileAddress, Status: FileSkipped}, nil
+	}
+
+	opt, err := getOptions(g.opts)
+	if err != nil {
+		return FileResult{}, err
+	}
+	// 枚举类型名需避开全部表名 (含未选中的表) 的 struct 名
+	opt = append(opt, parser.WithSchema(schema), parser.WithTableNames(g.tableNames...))
+	// 原文件不存在时从 1 开始编号
+	previous, err := os.ReadFile(fileAddress)
+	if err != nil && !os.IsNotExist(err) {
+		return FileResult{}, fmt.Errorf("read %s failed, %w", fileAddress, err)
+	}
+
+	if !g.opts.DryRun {
+		fmt.Fprintln(g.out, color.Yellow("正在生成 ["+table.Name+"]"))
+	}
+	buf := &bytes.Buffer{}
+	if err = parser.ParseProtoToWrite(table, previous, buf, opt...); err != nil {
+		return FileResult{}, err
+	}
+	file, err := g.writeGeneratedFile(fileAddress, buf.Bytes())
+	if err != nil {
+		return file, err
+	}
+	if !g.opts.DryRun {
+		fmt.Fprintln(g.out, color.Green("生成完毕 ["+table.Name+"]"))
+	}
+	return file, nil
+}
+
+//writeFormatFile 将表的 JSON Schema、OpenAPI components.schemas 或 TypeScript interface 写入文件
+func (g *Generator) writeFormatFile(table *parser.Table, schema []*parser.Table) (FileResult, error) {
+	dirPath, err := g.initDirPath(g.opts.OutputPath)
+	if err != nil {
+		return FileResult{}, fmt.Errorf("init dir path %s failed, %w", g.opts.OutputPath, err)
+	}
+
+	write, ext := parser.ParseJSONSchemaToWrite, ".schema.json"
+	switch g.opts.Format {
+	case FormatOpenAPI:
+		write, ext = parser.ParseOpenAPIToWrite, ".yaml"
+	case FormatTypeScript:
+		write, ext = parser.ParseTypeScriptToWrite, ".ts"
+	}
+	//如果文件已存在 则 跳过
+	fileAddress := formatFilePath(table.Name, g.opts.TablePrefix, dirPath, ext)
+	if g.skipExistingFile(table.Name, fileAddress) {
+		return FileResult{Path: fileAddress, Status: FileSkipped}, nil
 	}
 
-	err = parser.ParseSQLToWrite(sql, f, opt...)
+	opt, err := getOptions(g.opts)
+	if err != nil {
+		return FileResult{}, err
+	}
+	// 枚举类型名需避开全部表名 (含未选中的表) 的 struct 名
+	opt = append(opt, parser.WithSchema(schema), parser.WithTableNames(g.tableNames...))
+	if !g.opts.DryRun {
+		fmt.Fprintln(g.out, color.Yellow("正在生成 ["+table.Name+"]"))
+	}
+	buf := &bytes.Buffer{}
+	if err = write(table, buf, opt...); err != nil {
+		return FileResult{}, err
+	}
+	file, err := g.writeGeneratedFile(fileAddress, buf.Bytes())
 	if err != nil {
-		exitWithInfo(err.Error())
+		return file, err
+	}
+	if !g.opts.DryRun {
+		fmt.Fprintln(g.out, color.Green("生成完毕 ["+table.Name+"]"))
+	}
+	return file, nil
+}
+
+// writeGeneratedFile 写入生成的代码, 原 go 文件中 gmodel:begin custom 区域及 gmodel:"keep" 字段保留;
+// 先写临时文件再重命名, 失败时原文件不变; --dry-run 时不写入
+func (g *Generator) writeGeneratedFile(fileAddress string, code []byte) (FileResult, error) {
+	file := FileResult{Path: fileAddress, Status: FileCreated}
+	old, err := os.ReadFile(fileAddress)
+	if err == nil {
+		file.Old = old
+		if len(old) > 0 && strings.HasSuffix(fileAddress, ".go") {
+			merged, err := parser.MergeCode(old, code)
+			if err != nil {
+				return file, fmt.Errorf("keep custom code of %s failed, %w", fileAddress, err)
+			}
+			code = merged
+		}
+		file.Status = FileChanged
+		if bytes.Equal(old, code) {
+			file.Status = FileUnchanged
+		}
+	}
+	file.Code = code
+
+	if g.opts.DryRun {
+		return file, nil
+	}
+	tmp := fileAddress + ".tmp"
+	if err := os.WriteFile(tmp, code, os.ModePerm); err != nil {
+		_ = os.Remove(tmp)
+		return file, fmt.Errorf("write %s failed, %w", fileAddress, err)
+	}
+	if err := os.Rename(tmp, fileAddress); err != nil {
+		_ = os.Remove(tmp)
+		return file, fmt.Errorf("write %s failed, %w", fileAddress, err)
 	}
+	return file, nil
 }
 
-// initDirPath .
-func initDirPath(dirPath string) (string, error) {
+// initDirPath dry run 时不创建目录
+func (g *Generator) initDirPath(dirPath string) (string, error) {
 	if dirPath == "" {
 		dirPath = "./"
-	} else {
+	} else if !g.opts.DryRun {
 		if ok, _ := pathExists(dirPath); !ok {
-			err := os.MkdirAll(dirPath, os.ModePerm)
-			return "", err
+			if err := os.MkdirAll(dirPath, os.ModePerm); err != nil {
+				return "", err
+			}
 		}
 	}
 	return dirPath, nil
@@ -193,47 +763,176 @@ func modelTip() {
 	fmt.Printf("%s\n", color.Blue(`In the making...`))
 }
 
-// ExitWithInfo .
-func exitWithInfo(format string, a ...interface{}) {
-	_, _ = fmt.Fprintf(os.Stderr, format+"\n", a...)
-	os.Exit(1)
-}
-
 //judgeUpdateArgs 判断 update 命令是否配合 -t / -e
-func judgeUpdateArgs() {
-	if modelArgs.Update {
-		if !modelArgs.Enforcement && (modelArgs.MysqlTable == "" || modelArgs.MysqlTable == "*") {
-			_, _ = fmt.Fprintf(os.Stderr, "no table or enforcement input(-t|-e)\n\n")
-			flag.Usage()
-			os.Exit(2)
+func judgeUpdateArgs(args *ModelOptions) error {
+	if args.Update {
+		if !args.Enforcement && (args.MysqlTable == "" || args.MysqlTable == "*") {
+			return fmt.Errorf("no table or enforcement input(-t|-e)")
 		}
 	}
+	return nil
 }
 
 //judgeMysqlDsnIsNull 判断 dsn 连接是否为空
-func judgeMysqlDsnIsNull() {
-	if modelArgs.MysqlDsn == "" {
-		exitWithInfo("miss mysql conn, please add a configuration")
+func judgeMysqlDsnIsNull(args *ModelOptions) error {
+	if args.MysqlDsn == "" {
+		return fmt.Errorf("miss mysql conn, please add a configuration")
 	}
+	return nil
 }
 
-//judgeMysqlSqlWithTable 获取sql 并 判断 sql 是否配合-t使用
-func judgeMysqlSqlWithTable() {
-	if modelArgs.SQL == "" {
-		if modelArgs.InputFile != "" {
-			b, err := os.ReadFile(modelArgs.InputFile)
+//judgeMysqlSqlWithTable 获取sql, 一个 sql 可包含多个表, -t 可选
+func judgeMysqlSqlWithTable(args *ModelOptions) error {
+	if args.SQL == "" {
+		if args.InputFile != "" {
+			b, err := os.ReadFile(args.InputFile)
 			if err != nil {
-				exitWithInfo("read %s failed, %s\n", modelArgs.InputFile, err)
+				return fmt.Errorf("read %s failed, %w", args.InputFile, err)
 			}
-			modelArgs.SQL = string(b)
+			args.SQL = string(b)
+		}
+	}
+	return nil
+}
+
+//judgeFormatArgs 检查输出格式, 只有 go 格式可以生成 dao
+func judgeFormatArgs(args *ModelOptions) error {
+	switch args.Format {
+	case "", FormatGo:
+		args.Format = FormatGo
+		return nil
+	case FormatProto, FormatJSONSchema, FormatOpenAPI, FormatTypeScript:
+	default:
+		return fmt.Errorf("unsupported format: %s", args.Format)
+	}
+	if args.Dao {
+		return fmt.Errorf("dao needs go models, it can not be generated with format %s", args.Format)
+	}
+	return nil
+}
+
+//judgeFlavorArgs 检查 model 风格, dao 与关联字段只支持 gorm, 转换函数不支持 ent schema
+func judgeFlavorArgs(args *ModelOptions) error {
+	flavor, err := parser.ParseFlavor(args.Flavor)
+	if err != nil {
+		return err
+	}
+	args.Flavor = string(flavor)
+	if flavor == parser.FlavorGorm {
+		return nil
+	}
+	if args.Format != FormatGo {
+		return fmt.Errorf("flavor %s applies to go models, it can not be used with format %s", flavor, args.Format)
+	}
+	if args.Dao {
+		return fmt.Errorf("dao uses gorm, it can not be generated with flavor %s", flavor)
+	}
+	if args.Associations || args.HasMany {
+		return fmt.Errorf("associations are gorm fields, they can not be generated with flavor %s", flavor)
+	}
+	if args.Convert != "" && flavor == parser.FlavorEnt {
+		return fmt.Errorf("ent writes schemas instead of structs, converters can not be generated with flavor ent")
+	}
+	return nil
+}
+
+//judgeDaoArgs 补全 dao 输出目录、包名和 model 包的 import path
+func judgeDaoArgs(args *ModelOptions) error {
+	if !args.Dao {
+		return nil
+	}
+	if args.DaoPath == "" {
+		args.DaoPath = "./dao"
+	}
+	if args.DaoPackage == "" {
+		args.DaoPackage = filepath.Base(args.DaoPath)
+	}
+
+	modelDir, _ := filepath.Abs(defaultString(args.OutputPath, "./"))
+	daoDir, _ := filepath.Abs(args.DaoPath)
+	if modelDir == daoDir {
+		return fmt.Errorf("dao path must be different from output path: %s", args.DaoPath)
+	}
+
+	return judgeModelImport(args, modelDir)
+}
+
+//judgeConvertArgs 检查转换目标, 补全 convert 输出目录、包名及 pb / dto 包的 import path
+func judgeConvertArgs(args *ModelOptions) error {
+	switch args.Convert {
+	case "":
+		return nil
+	case parser.ConvertPB, parser.ConvertDTO:
+	default:
+		return fmt.Errorf("unsupported convert target: %s, use pb or dto", args.Convert)
+	}
+	if args.Format != FormatGo {
+		return fmt.Errorf("converters need go models, they can not be generated with format %s", args.Format)
+	}
+	if args.ConvertImport == "" && args.Convert == parser.ConvertPB {
+		args.ConvertImport = args.ProtoGoPackage
+	}
+	if args.ConvertImport == "" {
+		return fmt.Errorf("the import path of the %s package is required, please set convert_import", args.Convert)
+	}
+	if args.ConvertPath == "" {
+		args.ConvertPath = "./convert"
+	}
+	if args.ConvertPackage == "" {
+		args.ConvertPackage = filepath.Base(args.ConvertPath)
+	}
+
+	modelDir, _ := filepath.Abs(defaultString(args.OutputPath, "./"))
+	convertDir, _ := filepath.Abs(args.ConvertPath)
+	if modelDir == convertDir {
+		return fmt.Errorf("convert path must be different from output path: %s", args.ConvertPath)
+	}
+	return judgeModelImport(args, modelDir)
+}
+
+//judgeModelImport 未设置 model_import 时根据 go.mod 推导 model 包的 import path
+func judgeModelImport(args *ModelOptions, modelDir string) error {
+	if args.ModelImport == "" {
+		importPath, err := modelImportPath(modelDir)
+		if err != nil {
+			return fmt.Errorf("%w, please set model_import", err)
 		}
+		args.ModelImport = importPath
 	}
+	return nil
+}
 
-	//如果指定 sql 语句；则 -t 必须存在， -t 存在 sql 不一定存在;  不支持 一个 sql 多个table的用法
-	if modelArgs.SQL != "" {
-		if modelArgs.MysqlTable == "" || modelArgs.MysqlTable == "*" {
-			exitWithInfo("you need use -t for a table name")
+// modelImportPath 根据 go.mod 推导目录的 import path
+func modelImportPath(dir string) (string, error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		b, err := os.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(b), "\n") {
+				line = strings.TrimSpace(line)
+				if strings.HasPrefix(line, "module ") {
+					rel, err := filepath.Rel(d, dir)
+					if err != nil {
+						return "", err
+					}
+					module := strings.Trim(strings.TrimSpace(line[len("module "):]), `"`)
+					if rel == "." {
+						return module, nil
+					}
+					return module + "/" + filepath.ToSlash(rel), nil
+				}
+			}
+			return "", fmt.Errorf("module not found in %s", filepath.Join(d, "go.mod"))
 		}
-		return
+		if filepath.Dir(d) == d {
+			return "", fmt.Errorf("go.mod not found for %s", dir)
+		}
+	}
+}
+
+// defaultString .
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
 	}
+	return s
 }
@@ -0,0 +1,408 @@
+package gmodel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGeneratorTableErrors(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "struct.tmpl")
+	err := os.WriteFile(tmpl, []byte(`{{if eq .RawTableName "bad"}}{{.Missing}}{{end}}type {{.TableName}} struct{}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGenerator(ModelOptions{
+		SQL:            "CREATE TABLE good (id int NOT NULL); CREATE TABLE bad (id int NOT NULL);",
+		OutputPath:     filepath.Join(dir, "model"),
+		StructTemplate: tmpl,
+		Package:        "model",
+	})
+	g.SetOutput(io.Discard)
+	result, err := g.Generate(context.Background())
+	var genErr *GenerateError
+	if !errors.As(err, &genErr) {
+		t.Fatalf("Generate() error = %v, want *GenerateError", err)
+	}
+	if genErr.Total != 2 || len(genErr.Errors) != 1 || genErr.Errors[0].Table != "bad" {
+		t.Errorf("unexpected error: %+v", genErr)
+	}
+	if len(result.Tables) != 2 || result.Tables[0].Table != "good" || result.Tables[0].Err != nil ||
+		len(result.Tables[0].Files) != 1 || result.Tables[0].Files[0].Status != FileCreated {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "model", "good.go")); err != nil {
+		t.Errorf("good.go should be written: %s", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "model", "bad.go")); !os.IsNotExist(err) {
+		t.Errorf("bad.go should not be written: %v", err)
+	}
+}
+
+func TestGeneratorTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	g := NewGenerator(ModelOptions{
+		SQL:         "CREATE TABLE users (id int NOT NULL);",
+		OutputPath:  dir,
+		TemplateDir: filepath.Join(dir, "tmpl"),
+	})
+	g.SetOutput(io.Discard)
+	if _, err := g.Generate(context.Background()); err == nil || !strings.Contains(err.Error(), "template dir") {
+		t.Errorf("a missing template dir should fail, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "users.go")); !os.IsNotExist(err) {
+		t.Errorf("users.go should not be written with the built-in template: %v", err)
+	}
+}
+
+func TestGeneratorConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	wg := &sync.WaitGroup{}
+	results := make([]Result, 2)
+	errs := make([]error, 2)
+	for i, pkg := range []string{"a", "b"} {
+		i, pkg := i, pkg
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g := NewGenerator(ModelOptions{
+				SQL:        "CREATE TABLE users (id int NOT NULL, name varchar(32) NOT NULL);",
+				OutputPath: filepath.Join(dir, pkg),
+				Package:    pkg,
+				JSONTag:    pkg == "a",
+			})
+			g.SetOutput(io.Discard)
+			results[i], errs[i] = g.Generate(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	for i, pkg := range []string{"a", "b"} {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		file := results[i].Tables[0].Files[0]
+		b, err := os.ReadFile(filepath.Join(dir, pkg, "users.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != string(file.Code) {
+			t.Errorf("%s: result code differs from the written file", pkg)
+		}
+	}
+	if string(results[0].Tables[0].Files[0].Code) == string(results[1].Tables[0].Files[0].Code) {
+		t.Error("generators should not share options")
+	}
+
+	// 再次生成未指定 -u 时跳过
+	g := NewGenerator(ModelOptions{
+		SQL:        "CREATE TABLE users (id int NOT NULL); CREATE TABLE posts (id int NOT NULL);",
+		OutputPath: filepath.Join(dir, "a"),
+	})
+	g.SetOutput(io.Discard)
+	result, err := g.Generate(context.Background())
+	if err != nil || result.Tables[0].Files[0].Status != FileSkipped {
+		t.Errorf("existing users.go should be skipped without -u: %+v, %v", result, err)
+	}
+
+	// check 时已存在的文件也对比, 不写入
+	for _, sql := range []string{
+		"CREATE TABLE users (id int NOT NULL, name varchar(32) NOT NULL); CREATE TABLE posts (id int NOT NULL);",
+		"CREATE TABLE users (id int NOT NULL, name varchar(32) NOT NULL, age int NOT NULL);",
+	} {
+		g = NewGenerator(ModelOptions{SQL: sql, OutputPath: filepath.Join(dir, "b"), Package: "b", Check: true})
+		g.SetOutput(io.Discard)
+		result, err = g.Generate(context.Background())
+		if err == nil {
+			t.Errorf("check should fail when a file would change: %+v", result)
+		}
+	}
+	if result.Tables[0].Files[0].Status != FileChanged {
+		t.Errorf("the stale users.go should be changed: %+v", result)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "b", "posts.go")); !os.IsNotExist(err) {
+		t.Errorf("dry run should not write posts.go: %v", err)
+	}
+	g = NewGenerator(ModelOptions{SQL: "CREATE TABLE users (id int NOT NULL, name varchar(32) NOT NULL);",
+		OutputPath: filepath.Join(dir, "b"), Package: "b", Check: true})
+	g.SetOutput(io.Discard)
+	if result, err = g.Generate(context.Background()); err != nil || result.Tables[0].Files[0].Status != FileUnchanged {
+		t.Errorf("check should pass when users.go is up to date: %+v, %v", result, err)
+	}
+}
+
+func TestGeneratorTableFilter(t *testing.T) {
+	dir := t.TempDir()
+	g := NewGenerator(ModelOptions{
+		SQL: "CREATE TABLE users (id int NOT NULL); CREATE TABLE user_roles (id int NOT NULL);" +
+			"CREATE TABLE user_bak (id int NOT NULL); CREATE TABLE orders (id int NOT NULL);",
+		MysqlTable:    "user_*,orders",
+		ExcludeTables: []string{"*_bak"},
+		OutputPath:    dir,
+	})
+	g.SetOutput(io.Discard)
+	result, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for _, table := range result.Tables {
+		tables = append(tables, table.Table)
+	}
+	if strings.Join(tables, ",") != "user_roles,orders" {
+		t.Errorf("generated %v, want user_roles and orders", tables)
+	}
+
+	g = NewGenerator(ModelOptions{SQL: "CREATE TABLE users (id int NOT NULL);", MysqlTable: "tmp_*", OutputPath: dir})
+	g.SetOutput(io.Discard)
+	if _, err = g.Generate(context.Background()); err == nil {
+		t.Error("no matching table should fail")
+	}
+}
+
+func TestGeneratorEnumTableNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, opts := range []ModelOptions{{}, {MysqlTable: "orders"}, {ExcludeTables: []string{"order_status"}}} {
+		opts.SQL = "CREATE TABLE orders (id int NOT NULL, status enum('new','paid') NOT NULL);" +
+			"CREATE TABLE order_status (id int NOT NULL);"
+		opts.OutputPath = dir
+		opts.DryRun = true
+		g := NewGenerator(opts)
+		g.SetOutput(io.Discard)
+		result, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		// -t 及 exclude_tables 选中部分表时枚举类型名与全部生成时一致, 不与 order_status 的 struct 重名
+		for _, res := range result.Tables {
+			if code := string(res.Files[0].Code); res.Table == "orders" && !strings.Contains(code, "type OrderStatusType string") {
+				t.Errorf("%+v: the enum type should avoid OrderStatus:\n%s", opts, code)
+			}
+		}
+	}
+}
+
+func TestGeneratorViews(t *testing.T) {
+	dir := t.TempDir()
+	dsn := filepath.Join(dir, "test.db")
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE VIEW user_names AS SELECT name FROM users",
+	} {
+		if _, err = db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	generated := func(opts ModelOptions) string {
+		opts.MysqlDsn = dsn
+		opts.OutputPath = filepath.Join(dir, "model")
+		opts.DryRun = true
+		g := NewGenerator(opts)
+		g.SetOutput(io.Discard)
+		result, err := g.Generate(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tables []string
+		for _, table := range result.Tables {
+			tables = append(tables, table.Table)
+		}
+		return strings.Join(tables, ",")
+	}
+	if got := generated(ModelOptions{}); got != "users" {
+		t.Errorf("views should be skipped by default, generated %s", got)
+	}
+	if got := generated(ModelOptions{Views: true}); got != "user_names,users" {
+		t.Errorf("generated %s with views, want user_names,users", got)
+	}
+	if got := generated(ModelOptions{MysqlTable: "user_names"}); got != "user_names" {
+		t.Errorf("a view named by -t should be generated, got %s", got)
+	}
+}
+
+func TestGeneratorCanceled(t *testing.T) {
+	dir := t.TempDir()
+	dsn := filepath.Join(dir, "test.db")
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// 取消后不再读取表结构, 也不写入文件
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, bulk := range []bool{false, true} {
+		g := NewGenerator(ModelOptions{MysqlDsn: dsn, OutputPath: filepath.Join(dir, "model"), Bulk: bulk})
+		g.SetOutput(io.Discard)
+		if _, err = g.Generate(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("bulk %v: canceled Generate should fail with context.Canceled, got %v", bulk, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, "model", "users.go")); !os.IsNotExist(err) {
+		t.Errorf("users.go should not be written: %v", err)
+	}
+}
+
+func TestGeneratorProto(t *testing.T) {
+	dir := t.TempDir()
+	opts := ModelOptions{
+		SQL:         "CREATE TABLE users (id bigint NOT NULL, name varchar(20) NULL, PRIMARY KEY (id));",
+		OutputPath:  dir,
+		Format:      FormatProto,
+		Update:      true,
+		Enforcement: true,
+	}
+	g := NewGenerator(opts)
+	g.SetOutput(io.Discard)
+	if _, err := g.Generate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "users.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "google.protobuf.StringValue name = 2;") {
+		t.Errorf("unexpected users.proto:\n%s", b)
+	}
+
+	// the field numbers are read back from users.proto
+	g = NewGenerator(opts)
+	g.SetOutput(io.Discard)
+	result, err := g.Generate(context.Background())
+	if err != nil || result.Changed() {
+		t.Errorf("regenerating should leave users.proto unchanged: %+v, %v", result, err)
+	}
+
+	opts.Dao = true
+	g = NewGenerator(opts)
+	g.SetOutput(io.Discard)
+	if _, err = g.Generate(context.Background()); err == nil {
+		t.Error("dao with format proto should fail")
+	}
+}
+
+func TestGeneratorConvert(t *testing.T) {
+	dir := t.TempDir()
+	opts := ModelOptions{
+		SQL:            "CREATE TABLE users (id bigint NOT NULL, name varchar(20) NULL, PRIMARY KEY (id));",
+		OutputPath:     filepath.Join(dir, "model"),
+		ModelImport:    "example.com/app/model",
+		Convert:        "pb",
+		ProtoGoPackage: "example.com/app/pb",
+		ConvertPath:    filepath.Join(dir, "mapper"),
+		Update:         true,
+		Enforcement:    true,
+	}
+	g := NewGenerator(opts)
+	g.SetOutput(io.Discard)
+	if _, err := g.Generate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "mapper", "users.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"package mapper\n", `pb "example.com/app/pb"`, "func UsersFromPB(t *pb.Users) (*model.Users, error) {"} {
+		if !strings.Contains(string(b), s) {
+			t.Errorf("mapper/users.go should contain %q:\n%s", s, b)
+		}
+	}
+
+	opts.ProtoGoPackage = ""
+	g = NewGenerator(opts)
+	g.SetOutput(io.Discard)
+	if _, err = g.Generate(context.Background()); err == nil {
+		t.Error("a pb converter without the pb import path should fail")
+	}
+}
+
+func TestGeneratorFormats(t *testing.T) {
+	dir := t.TempDir()
+	for format, want := range map[string][2]string{
+		FormatJSONSchema: {"users.schema.json", `"maxLength": 20`},
+		FormatOpenAPI:    {"users.yaml", "maxLength: 20"},
+		FormatTypeScript: {"users.ts", "  name: string;"},
+	} {
+		g := NewGenerator(ModelOptions{
+			SQL:         "CREATE TABLE users (id bigint NOT NULL, name varchar(20) NOT NULL, PRIMARY KEY (id));",
+			OutputPath:  dir,
+			JSONTag:     true,
+			Format:      format,
+			Update:      true,
+			Enforcement: true,
+		})
+		g.SetOutput(io.Discard)
+		if _, err := g.Generate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(filepath.Join(dir, want[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), want[1]) {
+			t.Errorf("%s should contain %q:\n%s", want[0], want[1], b)
+		}
+	}
+}
+
+func TestGeneratorFlavor(t *testing.T) {
+	dir := t.TempDir()
+	for flavor, want := range map[string]string{
+		"bun": "bun.BaseModel `bun:\"table:users\"`",
+		"ent": `field.Int64("id").Immutable()`,
+	} {
+		opts := ModelOptions{
+			SQL:         "CREATE TABLE users (id bigint NOT NULL AUTO_INCREMENT, name varchar(20) NULL, PRIMARY KEY (id));",
+			OutputPath:  filepath.Join(dir, flavor),
+			Flavor:      flavor,
+			Update:      true,
+			Enforcement: true,
+		}
+		g := NewGenerator(opts)
+		g.SetOutput(io.Discard)
+		if _, err := g.Generate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(filepath.Join(dir, flavor, "users.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%s/users.go should contain %q:\n%s", flavor, want, b)
+		}
+	}
+
+	for _, opts := range []ModelOptions{
+		{Flavor: "sqlc"},
+		{Flavor: "sqlx", Dao: true},
+		{Flavor: "bun", Associations: true},
+		{Flavor: "ent", Convert: "dto", ConvertImport: "example.com/app/dto"},
+		{Flavor: "xorm", Format: FormatProto},
+	} {
+		opts.SQL = "CREATE TABLE users (id bigint NOT NULL, PRIMARY KEY (id));"
+		opts.OutputPath = filepath.Join(dir, "invalid")
+		g := NewGenerator(opts)
+		g.SetOutput(io.Discard)
+		if _, err := g.Generate(context.Background()); err == nil {
+			t.Errorf("flavor %s with %+v should fail", opts.Flavor, opts)
+		}
+	}
+}
@@ -7,15 +7,25 @@ import (
 )
 
 // initParamsFlags .
-func (conf *GModelsConf) initParamsFlags(modelCmd *cobra.Command) {
+func (conf *GModelsConf) initParamsFlags(modelCmd *cobra.Command, modelArgs *ModelOptions) {
 
 	//判断是否选定连接 -- 如果选定则使用，若没有选定则使用第一个连接
 	modelCmd.Flags().StringVar(&modelArgs.SelectMySQL, "slm", conf.DefaultMysql, "table name prefix")
 
-	defaultMysqlConf := (*confOption)[conf.DefaultMysql]
+	defaultMysqlConf := conf.confOption[conf.DefaultMysql]
 
 	modelCmd.Flags().StringVarP(&modelArgs.InputFile, "file", "f", "", "input file")
+	modelCmd.Flags().StringVarP(&modelArgs.MigrationDir, "migrations", "m", defaultMysqlConf.MigrationDir, "migration dir, replay its *.sql files in name order")
 	modelCmd.Flags().StringVarP(&modelArgs.OutputPath, "output", "o", defaultMysqlConf.OutputPath, "output path")
+	modelCmd.Flags().StringVar(&modelArgs.TemplateDir, "template-dir", defaultMysqlConf.TemplateDir, "template dir containing struct.tmpl and/or file.tmpl")
+	modelCmd.Flags().StringVar(&modelArgs.StructTemplate, "struct-template", defaultMysqlConf.StructTemplate, "struct template file")
+	modelCmd.Flags().StringVar(&modelArgs.FileTemplate, "file-template", defaultMysqlConf.FileTemplate, "file template file")
+	modelCmd.Flags().BoolVar(&modelArgs.Dao, "dao", defaultMysqlConf.Dao, "generate a gorm repository per table")
+	modelCmd.Flags().StringVar(&modelArgs.DaoPath, "dao-path", defaultMysqlConf.DaoPath, "dao output path, default: ./dao")
+	modelCmd.Flags().StringVar(&modelArgs.DaoPackage, "dao-pkg", defaultMysqlConf.DaoPackage, "dao package name, default: base name of dao path")
+	modelCmd.Flags().StringVar(&modelArgs.ModelImport, "model-import", defaultMysqlConf.ModelImport, "import path of the model package, detected from go.mod if empty")
+	modelCmd.Flags().BoolVar(&modelArgs.Associations, "associations", defaultMysqlConf.Associations, "turn foreign keys into BelongsTo fields")
+	modelCmd.Flags().BoolVar(&modelArgs.HasMany, "has-many", defaultMysqlConf.HasMany, "also write HasMany slices on the parent struct, implies --associations")
 	modelCmd.Flags().StringVarP(&modelArgs.SQL, "sql", "s", "", "input SQL")
 	modelCmd.Flags().BoolVarP(&modelArgs.JSONTag, "json", "j", defaultMysqlConf.JSONTag, "generate json tag")
 	modelCmd.Flags().StringVar(&modelArgs.TablePrefix, "table-prefix", defaultMysqlConf.TablePrefix, "table name prefix")
@@ -27,28 +37,76 @@ func (conf *GModelsConf) initParamsFlags(modelCmd *cobra.Command) {
 	modelCmd.Flags().BoolVar(&modelArgs.GormType, "with-type", defaultMysqlConf.GormType, "write type in gorm tag")
 	modelCmd.Flags().BoolVar(&modelArgs.ForceTableName, "with-tablename", true, "write TableName func force")
 	modelCmd.Flags().StringVarP(&modelArgs.MysqlDsn, "db-dsn", "d", defaultMysqlConf.MysqlDsn, "mysql dsn([user]:[pass]@tcp(host)/[database][?charset=xxx&...])")
-	modelCmd.Flags().StringVarP(&modelArgs.MysqlTable, "db-table", "t", defaultMysqlConf.MysqlTable, "mysql table name")
+	modelCmd.Flags().StringVar(&modelArgs.Driver, "driver", defaultMysqlConf.Driver, "database driver: mysql, postgres or sqlite, detected from dsn if empty")
+	modelCmd.Flags().IntVar(&modelArgs.Jobs, "jobs", defaultMysqlConf.Jobs, "tables read at the same time through one connection pool, default: 8")
+	modelCmd.Flags().BoolVar(&modelArgs.Bulk, "bulk", defaultMysqlConf.Bulk, "read mysql tables from information_schema in a few queries instead of SHOW CREATE TABLE per table")
+	modelCmd.Flags().StringVarP(&modelArgs.MysqlTable, "db-table", "t", defaultMysqlConf.MysqlTable, "mysql table name, or comma separated names, globs (user_*) and /regexp/")
+	modelCmd.Flags().StringSliceVar(&modelArgs.ExcludeTables, "exclude", defaultMysqlConf.ExcludeTables, "skip tables by comma separated names, globs (tmp_*) and /regexp/")
+	modelCmd.Flags().BoolVar(&modelArgs.Views, "views", defaultMysqlConf.Views, "also generate read-only models for the views")
+	modelCmd.Flags().StringVar(&modelArgs.Format, "format", defaultMysqlConf.Format, "output format: go (gorm models), proto (.proto messages), jsonschema (.schema.json), openapi (components.schemas .yaml) or ts (TypeScript interfaces)")
+	modelCmd.Flags().StringVar(&modelArgs.Flavor, "flavor", defaultMysqlConf.Flavor, "go model flavor: gorm, sqlx (db tags, Columns/ScanFields), bun, xorm or ent (schemas), default: gorm")
+	modelCmd.Flags().StringVar(&modelArgs.ProtoGoPackage, "go-package", defaultMysqlConf.ProtoGoPackage, "option go_package of the .proto files")
+	modelCmd.Flags().BoolVar(&modelArgs.TSBigIntString, "ts-bigint-string", defaultMysqlConf.TSBigIntString, "write bigint columns as string in the TypeScript interfaces")
+	modelCmd.Flags().StringVar(&modelArgs.Convert, "convert", defaultMysqlConf.Convert, "generate converters between the models and pb (protoc-gen-go messages) or dto structs")
+	modelCmd.Flags().StringVar(&modelArgs.ConvertImport, "convert-import", defaultMysqlConf.ConvertImport, "import path of the pb or dto package, default: --go-package for pb")
+	modelCmd.Flags().StringVar(&modelArgs.ConvertPath, "convert-path", defaultMysqlConf.ConvertPath, "converter output path, default: ./convert")
+	modelCmd.Flags().StringVar(&modelArgs.ConvertPackage, "convert-pkg", defaultMysqlConf.ConvertPackage, "converter package name, default: base name of convert path")
 	modelCmd.Flags().BoolVarP(&modelArgs.Update, "update", "u", false, "update table struct switch -t/-e")
 	modelCmd.Flags().BoolVarP(&modelArgs.Enforcement, "enforcement", "e", false, "enforcement update all table struct switch -e")
+	modelCmd.Flags().BoolVar(&modelArgs.DryRun, "dry-run", false, "print a diff of the files that would be written, write nothing")
+	modelCmd.Flags().BoolVar(&modelArgs.Check, "check", false, "the same as --dry-run, exit 1 when any file would change")
 	modelCmd.Flags().BoolVar(&modelArgs.JudgeUnsigned, "unsigned", false, "Whether to determine an unsigned type")
 }
 
 //如果不是默认的连接 则获取选定信息
-func secondInitFlags(firstMysqlConf *ModelOptions, defaultSelectModel string) error {
-	if _, ok := (*confOption)[modelArgs.SelectMySQL]; !ok {
+func (conf *GModelsConf) secondInitFlags(firstMysqlConf *ModelOptions) error {
+	if _, ok := conf.confOption[firstMysqlConf.SelectMySQL]; !ok {
 		log.Printf("select mysql not exist")
 		return fmt.Errorf("select mysql not exist")
 	}
 
-	selectMysqlConf := (*confOption)[modelArgs.SelectMySQL]
-	defaultMysqlConf := (*confOption)[defaultSelectModel]
+	selectMysqlConf := conf.confOption[firstMysqlConf.SelectMySQL]
+	defaultMysqlConf := conf.confOption[conf.DefaultMysql]
 
 	if firstMysqlConf.InputFile == defaultMysqlConf.InputFile {
 		firstMysqlConf.InputFile = selectMysqlConf.InputFile
 	}
+	if firstMysqlConf.MigrationDir == defaultMysqlConf.MigrationDir {
+		firstMysqlConf.MigrationDir = selectMysqlConf.MigrationDir
+	}
 	if firstMysqlConf.OutputPath == defaultMysqlConf.OutputPath {
 		firstMysqlConf.OutputPath = selectMysqlConf.OutputPath
 	}
+	if firstMysqlConf.TemplateDir == defaultMysqlConf.TemplateDir {
+		firstMysqlConf.TemplateDir = selectMysqlConf.TemplateDir
+	}
+	if firstMysqlConf.StructTemplate == defaultMysqlConf.StructTemplate {
+		firstMysqlConf.StructTemplate = selectMysqlConf.StructTemplate
+	}
+	if firstMysqlConf.FileTemplate == defaultMysqlConf.FileTemplate {
+		firstMysqlConf.FileTemplate = selectMysqlConf.FileTemplate
+	}
+	if firstMysqlConf.Dao == defaultMysqlConf.Dao {
+		firstMysqlConf.Dao = selectMysqlConf.Dao
+	}
+	if firstMysqlConf.DaoPath == defaultMysqlConf.DaoPath {
+		firstMysqlConf.DaoPath = selectMysqlConf.DaoPath
+	}
+	if firstMysqlConf.DaoPackage == defaultMysqlConf.DaoPackage {
+		firstMysqlConf.DaoPackage = selectMysqlConf.DaoPackage
+	}
+	if firstMysqlConf.ModelImport == defaultMysqlConf.ModelImport {
+		firstMysqlConf.ModelImport = selectMysqlConf.ModelImport
+	}
+	if firstMysqlConf.Associations == defaultMysqlConf.Associations {
+		firstMysqlConf.Associations = selectMysqlConf.Associations
+	}
+	if firstMysqlConf.HasMany == defaultMysqlConf.HasMany {
+		firstMysqlConf.HasMany = selectMysqlConf.HasMany
+	}
+	if firstMysqlConf.TypeMapping == nil {
+		firstMysqlConf.TypeMapping = selectMysqlConf.TypeMapping
+	}
 	if firstMysqlConf.SQL == defaultMysqlConf.SQL {
 		firstMysqlConf.SQL = selectMysqlConf.SQL
 	}
@@ -79,6 +137,45 @@ func secondInitFlags(firstMysqlConf *ModelOptions, defaultSelectModel string) er
 	if firstMysqlConf.MysqlDsn == defaultMysqlConf.MysqlDsn {
 		firstMysqlConf.MysqlDsn = selectMysqlConf.MysqlDsn
 	}
+	if firstMysqlConf.Driver == defaultMysqlConf.Driver {
+		firstMysqlConf.Driver = selectMysqlConf.Driver
+	}
+	if firstMysqlConf.Jobs == defaultMysqlConf.Jobs {
+		firstMysqlConf.Jobs = selectMysqlConf.Jobs
+	}
+	if equalStrings(firstMysqlConf.ExcludeTables, defaultMysqlConf.ExcludeTables) {
+		firstMysqlConf.ExcludeTables = selectMysqlConf.ExcludeTables
+	}
+	if firstMysqlConf.Bulk == defaultMysqlConf.Bulk {
+		firstMysqlConf.Bulk = selectMysqlConf.Bulk
+	}
+	if firstMysqlConf.Views == defaultMysqlConf.Views {
+		firstMysqlConf.Views = selectMysqlConf.Views
+	}
+	if firstMysqlConf.Format == defaultMysqlConf.Format {
+		firstMysqlConf.Format = selectMysqlConf.Format
+	}
+	if firstMysqlConf.Flavor == defaultMysqlConf.Flavor {
+		firstMysqlConf.Flavor = selectMysqlConf.Flavor
+	}
+	if firstMysqlConf.ProtoGoPackage == defaultMysqlConf.ProtoGoPackage {
+		firstMysqlConf.ProtoGoPackage = selectMysqlConf.ProtoGoPackage
+	}
+	if firstMysqlConf.TSBigIntString == defaultMysqlConf.TSBigIntString {
+		firstMysqlConf.TSBigIntString = selectMysqlConf.TSBigIntString
+	}
+	if firstMysqlConf.Convert == defaultMysqlConf.Convert {
+		firstMysqlConf.Convert = selectMysqlConf.Convert
+	}
+	if firstMysqlConf.ConvertImport == defaultMysqlConf.ConvertImport {
+		firstMysqlConf.ConvertImport = selectMysqlConf.ConvertImport
+	}
+	if firstMysqlConf.ConvertPath == defaultMysqlConf.ConvertPath {
+		firstMysqlConf.ConvertPath = selectMysqlConf.ConvertPath
+	}
+	if firstMysqlConf.ConvertPackage == defaultMysqlConf.ConvertPackage {
+		firstMysqlConf.ConvertPackage = selectMysqlConf.ConvertPackage
+	}
 	if firstMysqlConf.MysqlTable == defaultMysqlConf.MysqlTable {
 		firstMysqlConf.MysqlTable = selectMysqlConf.MysqlTable
 	}
@@ -89,9 +186,63 @@ func secondInitFlags(firstMysqlConf *ModelOptions, defaultSelectModel string) er
 	if firstMysqlConf.Enforcement == defaultMysqlConf.Enforcement {
 		firstMysqlConf.Enforcement = selectMysqlConf.Enforcement
 	}
+	if firstMysqlConf.DryRun == defaultMysqlConf.DryRun {
+		firstMysqlConf.DryRun = selectMysqlConf.DryRun
+	}
+	if firstMysqlConf.Check == defaultMysqlConf.Check {
+		firstMysqlConf.Check = selectMysqlConf.Check
+	}
 	if firstMysqlConf.JudgeUnsigned == defaultMysqlConf.JudgeUnsigned {
 		firstMysqlConf.JudgeUnsigned = selectMysqlConf.JudgeUnsigned
 	}
 
 	return nil
 }
+
+// equalStrings .
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// configOnlyOptions 子命令只注册了部分 flag, 未注册的字段始终为零值, secondInitFlags 只在其等于默认连接的配置时复制;
+// 这些配置项直接取所选连接的配置, 与生成 model 时一致
+func (conf *GModelsConf) configOnlyOptions(cmd *cobra.Command, args *ModelOptions) {
+	selectMysqlConf := conf.confOption[args.SelectMySQL]
+	keys := []struct {
+		flag string
+		copy func()
+	}{
+		{"driver", func() { args.Driver = selectMysqlConf.Driver }},
+		{"jobs", func() { args.Jobs = selectMysqlConf.Jobs }},
+		{"views", func() { args.Views = selectMysqlConf.Views }},
+		{"table-prefix", func() { args.TablePrefix = selectMysqlConf.TablePrefix }},
+		{"col-prefix", func() { args.ColumnPrefix = selectMysqlConf.ColumnPrefix }},
+		{"no-null", func() { args.NoNullType = selectMysqlConf.NoNullType }},
+		{"null-style", func() { args.NullStyle = selectMysqlConf.NullStyle }},
+		{"unsigned", func() { args.JudgeUnsigned = selectMysqlConf.JudgeUnsigned }},
+		{"pkg", func() { args.Package = selectMysqlConf.Package }},
+		{"json", func() { args.JSONTag = selectMysqlConf.JSONTag }},
+		{"with-type", func() { args.GormType = selectMysqlConf.GormType }},
+		{"with-tablename", func() { args.ForceTableName = selectMysqlConf.ForceTableName }},
+		{"flavor", func() { args.Flavor = selectMysqlConf.Flavor }},
+		// 以下配置项没有 flag
+		{"", func() {
+			args.Charset = selectMysqlConf.Charset
+			args.Collation = selectMysqlConf.Collation
+			args.TypeMapping = selectMysqlConf.TypeMapping
+		}},
+	}
+	for _, key := range keys {
+		if key.flag == "" || cmd.Flags().Lookup(key.flag) == nil {
+			key.copy()
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package gmodel
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/xiaoqicheng/gmodel/color"
+	"github.com/xiaoqicheng/gmodel/parser"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// migrationVersionRe 匹配 migration 文件名的版本号前缀
+var migrationVersionRe = regexp.MustCompile(`^(\d+)_`)
+
+// migrateOptions migrate gen 特有参数
+type migrateOptions struct {
+	Dir  string
+	Name string
+}
+
+// newMigrateCmd 获取 gmodel migrate cmd
+func (conf *GModelsConf) newMigrateCmd() *cobra.Command {
+	modelArgs := &ModelOptions{}
+	migrateArgs := &migrateOptions{}
+	var migrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "generate migrations from the models",
+	}
+
+	var genCmd = &cobra.Command{
+		Use:          "gen",
+		Short:        "generate up/down sql moving the database schema to the models",
+		Example:      "gmodel migrate gen --slm default -o ./dao/internal --dir ./migrations --name add_amount",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := conf.secondInitFlags(modelArgs); err != nil {
+				return err
+			}
+			conf.configOnlyOptions(cmd, modelArgs)
+			return generateMigration(modelArgs, migrateArgs)
+		},
+	}
+
+	genCmd.Flags().StringVar(&modelArgs.SelectMySQL, "slm", conf.DefaultMysql, "select connection")
+
+	defaultMysqlConf := conf.confOption[conf.DefaultMysql]
+
+	genCmd.Flags().StringVarP(&modelArgs.OutputPath, "output", "o", defaultMysqlConf.OutputPath, "model path")
+	genCmd.Flags().StringVarP(&modelArgs.MysqlDsn, "db-dsn", "d", defaultMysqlConf.MysqlDsn, "mysql dsn([user]:[pass]@tcp(host)/[database][?charset=xxx&...])")
+	genCmd.Flags().StringVarP(&modelArgs.MysqlTable, "db-table", "t", defaultMysqlConf.MysqlTable, "mysql table name, or comma separated names, globs (user_*) and /regexp/")
+	genCmd.Flags().StringSliceVar(&modelArgs.ExcludeTables, "exclude", defaultMysqlConf.ExcludeTables, "skip tables by comma separated names, globs (tmp_*) and /regexp/")
+	genCmd.Flags().StringVar(&migrateArgs.Dir, "dir", defaultString(defaultMysqlConf.MigrationDir, "./migrations"), "migration dir")
+	genCmd.Flags().StringVar(&migrateArgs.Name, "name", "gmodel", "migration name")
+
+	migrateCmd.AddCommand(genCmd)
+	return migrateCmd
+}
+
+// generateMigration 对比 model 与数据库表结构, 在 migration 目录写入 <version>_<name>.up.sql 与 .down.sql
+func generateMigration(modelArgs *ModelOptions, migrateArgs *migrateOptions) error {
+	if err := judgeMysqlDsnIsNull(modelArgs); err != nil {
+		return err
+	}
+	if flavor, _ := parser.ParseFlavor(modelArgs.Flavor); flavor != parser.FlavorGorm {
+		return fmt.Errorf("%s reads the gorm tags of the models, flavor %s is not supported", "migrate gen", modelArgs.Flavor)
+	}
+
+	driver, err := parser.ParseDriver(modelArgs.Driver, modelArgs.MysqlDsn)
+	if err != nil {
+		return err
+	}
+	if driver != parser.DriverMySQL {
+		return fmt.Errorf("migrate gen supports mysql only, got %s", driver)
+	}
+
+	models, err := parser.ParseModelDir(defaultString(modelArgs.OutputPath, "./"))
+	if err != nil {
+		return fmt.Errorf("parse models error: %w", err)
+	}
+	// -t / --exclude 只生成选中表的 migration
+	filter, err := tableFilter(modelArgs)
+	if err != nil {
+		return err
+	}
+	selected := models[:0]
+	for _, model := range models {
+		if filter.Match(model.Table.Name) {
+			selected = append(selected, model)
+		}
+	}
+	models = selected
+	if len(models) == 0 {
+		return fmt.Errorf("no model found in %s", defaultString(modelArgs.OutputPath, "./"))
+	}
+
+	in, err := parser.NewIntrospector(driver, modelArgs.MysqlDsn, modelArgs.Jobs)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	names, err := in.Tables("*")
+	if err != nil {
+		return fmt.Errorf("get tables error: %w", err)
+	}
+	exists := make(map[string]bool, len(names))
+	for _, name := range names {
+		exists[name] = true
+	}
+	opt, err := getOptions(*modelArgs)
+	if err != nil {
+		return err
+	}
+	// 枚举类型名与生成时一样按全部表名避让
+	all, err := in.TableNames()
+	if err != nil {
+		return fmt.Errorf("get tables error: %w", err)
+	}
+	opt = append(opt, parser.WithTableNames(all...))
+	tables := make([]*parser.Table, 0, len(models))
+	for _, model := range models {
+		if !exists[model.Table.Name] {
+			continue
+		}
+		createSQL, err := in.CreateTable(model.Table.Name)
+		if err != nil {
+			return fmt.Errorf("get create table error: %w", err)
+		}
+		table, err := parser.GetTablesFromSQL(createSQL, opt...)
+		if err != nil {
+			return fmt.Errorf("parse create table error: %w", err)
+		}
+		tables = append(tables, table...)
+	}
+
+	migration, err := parser.MigrateModels(models, tables, opt...)
+	if err != nil {
+		return err
+	}
+	if len(migration.Up) == 0 {
+		fmt.Printf("%s \n", color.Blue(`no changes`))
+		return nil
+	}
+
+	if err = os.MkdirAll(migrateArgs.Dir, os.ModePerm); err != nil {
+		return fmt.Errorf("init dir path %s failed, %w", migrateArgs.Dir, err)
+	}
+	version, err := nextMigrationVersion(migrateArgs.Dir)
+	if err != nil {
+		return err
+	}
+	prefix := filepath.Join(migrateArgs.Dir, version+"_"+migrateArgs.Name)
+	for file, stmts := range map[string][]string{prefix + ".up.sql": migration.Up, prefix + ".down.sql": migration.Down} {
+		if err = os.WriteFile(file, []byte(strings.Join(stmts, "\n")+"\n"), 0644); err != nil {
+			return fmt.Errorf("write %s failed, %w", file, err)
+		}
+		fmt.Println(color.Green("生成完毕 [" + file + "]"))
+	}
+	return nil
+}
+
+// nextMigrationVersion 返回目录中最大版本号加一, 位数与已有文件一致, 默认 4 位
+func nextMigrationVersion(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	max, width := 0, 4
+	for _, entry := range entries {
+		match := migrationVersionRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if v, err := strconv.Atoi(match[1]); err == nil && v >= max {
+			max, width = v, len(match[1])
+		}
+	}
+	return fmt.Sprintf("%0*d", width, max+1), nil
+}
@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/xiaoqicheng/gmodel/parser"
 	"os"
 )
 
 // ModelOptions .
 type ModelOptions struct {
-	MysqlDsn       string `json:"-" mapstructure:"dsn"` // mysql conn address
+	MysqlDsn       string `json:"-" mapstructure:"dsn"`    // mysql conn address
+	Driver         string `json:"-" mapstructure:"driver"` // mysql, postgres or sqlite, detected from dsn if empty
+	Jobs           int    `json:"-" mapstructure:"jobs"`   // tables read at the same time through one connection pool
+	Bulk           bool   `json:"-" mapstructure:"bulk"`   // read all mysql tables from information_schema in a few queries
 	MysqlTable     string `json:"-" mapstructure:"table"`
 	Charset        string `json:"-" mapstructure:"charset"` // charset
 	Collation      string `json:"-" mapstructure:"collation"`
@@ -20,24 +24,59 @@ type ModelOptions struct {
 	GormType       bool   `json:"-" mapstructure:"gorm_type"`
 	ForceTableName bool   `json:"-" mapstructure:"with_table"`
 	OutputPath     string `json:"-" mapstructure:"output_path"`
+	TemplateDir    string `json:"-" mapstructure:"template_dir"`    // dir of struct.tmpl / file.tmpl
+	StructTemplate string `json:"-" mapstructure:"struct_template"` // replaces the built-in struct template
+	FileTemplate   string `json:"-" mapstructure:"file_template"`   // replaces the built-in file template
+	Dao            bool   `json:"-" mapstructure:"dao"`             // generate a repository per table
+	DaoPath        string `json:"-" mapstructure:"dao_path"`        // default ./dao
+	DaoPackage     string `json:"-" mapstructure:"dao_pkg"`         // default base name of dao_path
+	ModelImport    string `json:"-" mapstructure:"model_import"`    // import path of the model package, detected from go.mod if empty
+	Associations   bool   `json:"-" mapstructure:"associations"`    // foreign keys become BelongsTo fields
+	HasMany        bool   `json:"-" mapstructure:"has_many"`        // also write HasMany slices on the parent, implies associations
 	SQL            string `json:"-"`
 	InputFile      string `json:"-"`
+	MigrationDir   string `json:"-" mapstructure:"migration_dir"` // ordered *.sql migration files replayed offline
 	NoNullType     bool   `json:"-"`
 	NullStyle      string `json:"-"`
 	Update         bool   `json:"-"`
 	Enforcement    bool   `json:"-"`
+	DryRun         bool   `json:"-"`                         // print the diff of the files instead of writing them
+	Check          bool   `json:"-"`                         // dry run, exit 1 if any file would change
 	JudgeUnsigned  bool   `json:"-" mapstructure:"unsigned"` //是否判断无符号 若为TRUE 则生成 uint类型; FALSE 为 int; default false
 	SelectMySQL    string `json:"-"`                         //是否指定数据库
-}
 
-var modelArgs = ModelOptions{}
-var confOption = &map[string]ModelOptions{}
+	// TypeMapping forces go types by sql type or table.column glob
+	TypeMapping []parser.TypeMapping `json:"-" mapstructure:"type_mapping"`
+	// ExcludeTables are skipped before any DDL is read, names, globs or /regexp/
+	ExcludeTables []string `json:"-" mapstructure:"exclude_tables"`
+	// Views generates read-only models for the views, they are skipped unless named by -t
+	Views bool `json:"-" mapstructure:"views"`
+	// Format of the generated files: go (gorm models, default), proto (one .proto message per table),
+	// jsonschema (one JSON Schema per table), openapi (one components.schemas yaml per table) or ts (one TypeScript interface per table)
+	Format string `json:"-" mapstructure:"format"`
+	// ProtoGoPackage is written as option go_package in the .proto files
+	ProtoGoPackage string `json:"-" mapstructure:"proto_go_package"`
+	// Convert generates converters between the models and pb (the protoc-gen-go messages) or dto structs
+	Convert string `json:"-" mapstructure:"convert"`
+	// ConvertImport is the import path of the pb or dto package, proto_go_package by default for pb
+	ConvertImport string `json:"-" mapstructure:"convert_import"`
+	// ConvertPath is the dir of the converters, default ./convert
+	ConvertPath string `json:"-" mapstructure:"convert_path"`
+	// ConvertPackage is the package name of the converters, default base name of convert_path
+	ConvertPackage string `json:"-" mapstructure:"convert_pkg"`
+	// TSBigIntString writes bigint columns as string in the TypeScript interfaces
+	TSBigIntString bool `json:"-" mapstructure:"ts_bigint_string"`
+	// Flavor is the library of the go models: gorm, sqlx, bun, xorm or ent, default gorm
+	Flavor string `json:"-" mapstructure:"flavor"`
+}
 
 type GModelsConf struct {
 	Path         string `json:"path"`
 	Name         string `json:"name"`
 	Type         string `json:"type"`
 	DefaultMysql string `json:"default_mysql"`
+
+	confOption map[string]ModelOptions // gmodel 配置中的各个连接
 }
 
 const (
@@ -120,6 +159,7 @@ func (conf *GModelsConf) NewGModelCmd() *cobra.Command {
 		return &cobra.Command{}
 	}
 
+	modelArgs := &ModelOptions{}
 	var modelCmd = &cobra.Command{
 		Use:          "gmodel",
 		Short:        "generate model",
@@ -129,12 +169,18 @@ func (conf *GModelsConf) NewGModelCmd() *cobra.Command {
 			modelTip()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			generateModel(conf.DefaultMysql)
-			return nil
+			//选择非默认连接信息 进行二次赋值
+			if err := conf.secondInitFlags(modelArgs); err != nil {
+				return err
+			}
+			_, err := NewGenerator(*modelArgs).Generate(cmd.Context())
+			return err
 		},
 	}
 
-	conf.initParamsFlags(modelCmd)
+	conf.initParamsFlags(modelCmd, modelArgs)
+	modelCmd.AddCommand(conf.newDiffCmd())
+	modelCmd.AddCommand(conf.newMigrateCmd())
 
 	return modelCmd
 }
@@ -149,11 +195,12 @@ func (conf *GModelsConf) parseConfig() error {
 		return fmt.Errorf("Read  gmodel cmd config file error: %s, so you can not use gmodel cmd", err)
 	}
 
-	if err := gmviper.UnmarshalKey("gmodel", confOption); err != nil {
+	conf.confOption = make(map[string]ModelOptions)
+	if err := gmviper.UnmarshalKey("gmodel", &conf.confOption); err != nil {
 		return fmt.Errorf("Parse config.gmodel segment error: %s\n", err)
 	}
 
-	if _, ok := (*confOption)[conf.DefaultMysql]; !ok {
+	if _, ok := conf.confOption[conf.DefaultMysql]; !ok {
 		return fmt.Errorf("Parse config.gmodel.default  not exits")
 	}
 
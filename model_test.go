@@ -3,6 +3,7 @@ package gmodel
 import (
 	"bytes"
 	"github.com/spf13/cobra"
+	"strings"
 	"testing"
 )
 
@@ -44,3 +45,28 @@ func executeCommandC(root *cobra.Command, args ...string) (c *cobra.Command, out
 
 	return c, buf.String(), err
 }
+
+func TestSubcommandConfigOptions(t *testing.T) {
+	conf := &GModelsConf{
+		DefaultMysql: "default",
+		confOption: map[string]ModelOptions{
+			"default": {MysqlDsn: "root:@tcp(127.0.0.1:1)/shop", Flavor: "sqlx", TablePrefix: "tbl_"},
+		},
+	}
+	for _, args := range [][]string{{"diff"}, {"migrate", "gen"}} {
+		rootCmd := &cobra.Command{Use: "gmodel"}
+		rootCmd.AddCommand(conf.newDiffCmd(), conf.newMigrateCmd())
+		_, err := executeCommand(rootCmd, args...)
+		// flavor 只在配置中, 子命令需读到 sqlx 并拒绝
+		if err == nil || !strings.Contains(err.Error(), "flavor sqlx is not supported") {
+			t.Errorf("%v: want the error of flavor sqlx from the config, got %v", args, err)
+		}
+	}
+
+	cmd := conf.newDiffCmd()
+	args := &ModelOptions{SelectMySQL: "default", TablePrefix: "t_"}
+	conf.configOnlyOptions(cmd, args)
+	if args.Flavor != "sqlx" || args.TablePrefix != "t_" {
+		t.Errorf("flavor should come from the config and --table-prefix from the flag: %+v", args)
+	}
+}
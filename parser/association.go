@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/inflection"
+)
+
+// makeAssociations builds the BelongsTo field of every foreign key of table whose parent is in the schema,
+// and with HasMany the slices of the children referencing table.
+func makeAssociations(table *Table, fields []TmplField, opt options) []TmplField {
+	used := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		used[f.Name] = true
+	}
+
+	var associations []TmplField
+	for _, fk := range table.ForeignKeys {
+		parent := schemaTable(opt.Schema, fk.RefTable)
+		if parent == nil {
+			continue
+		}
+		refColumns, ok := foreignKeyReferences(fk, parent)
+		if !ok {
+			continue
+		}
+		parentName := structName(parent.Name, opt)
+		name := uniqueFieldName(foreignKeyName(fk, parentName, opt), used)
+		associations = append(associations, TmplField{
+			Name:    name,
+			GoType:  "*" + parentName,
+			Tag:     associationTag(name, fieldNames(fk.Columns, opt), fieldNames(refColumns, opt), opt),
+			Comment: "belongs to " + parent.Name,
+		})
+	}
+
+	if !opt.HasMany {
+		return associations
+	}
+	for _, child := range opt.Schema {
+		for _, fk := range child.ForeignKeys {
+			if fk.RefTable != table.Name {
+				continue
+			}
+			refColumns, ok := foreignKeyReferences(fk, table)
+			if !ok {
+				continue
+			}
+			childName := structName(child.Name, opt)
+			name := inflection.Plural(childName)
+			// several foreign keys of one child are told apart by the foreign key name, e.g. CreatedByOrders
+			if used[name] || countReferences(child, table.Name) > 1 {
+				name = foreignKeyName(fk, "", opt) + name
+			}
+			name = uniqueFieldName(name, used)
+			associations = append(associations, TmplField{
+				Name:    name,
+				GoType:  "[]" + childName,
+				Tag:     associationTag(name, fieldNames(fk.Columns, opt), fieldNames(refColumns, opt), opt),
+				Comment: "has many " + child.Name,
+			})
+		}
+	}
+	return associations
+}
+
+// schemaTable .
+func schemaTable(schema []*Table, name string) *Table {
+	for _, t := range schema {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// foreignKeyReferences returns the referenced columns, the primary key of parent if they are implicit.
+func foreignKeyReferences(fk *ForeignKey, parent *Table) ([]string, bool) {
+	refColumns := fk.RefColumns
+	if len(refColumns) == 0 {
+		for _, col := range parent.Columns {
+			if col.PrimaryKey {
+				refColumns = append(refColumns, col.Name)
+			}
+		}
+	}
+	if len(refColumns) != len(fk.Columns) {
+		return nil, false
+	}
+	for _, col := range refColumns {
+		if parent.column(col) < 0 {
+			return nil, false
+		}
+	}
+	return refColumns, true
+}
+
+// foreignKeyName names the BelongsTo field: user_id is User, created_by is CreatedByUser
+// when parentName is User, a composite key is named after the parent.
+func foreignKeyName(fk *ForeignKey, parentName string, opt options) string {
+	parentName = inflection.Singular(parentName)
+	if len(fk.Columns) != 1 {
+		return parentName
+	}
+	col := fk.Columns[0]
+	if strings.HasSuffix(strings.ToLower(col), "_id") && len(col) > len("_id") {
+		return fieldName(col[:len(col)-len("_id")], opt)
+	}
+	return fieldName(col, opt) + parentName
+}
+
+// countReferences returns the number of foreign keys of child referencing parent.
+func countReferences(child *Table, parent string) int {
+	n := 0
+	for _, fk := range child.ForeignKeys {
+		if fk.RefTable == parent {
+			n++
+		}
+	}
+	return n
+}
+
+// uniqueFieldName appends a number to name until it's not used, and marks it used.
+func uniqueFieldName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// fieldNames .
+func fieldNames(columns []string, opt options) []string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, fieldName(col, opt))
+	}
+	return names
+}
+
+// associationTag .
+func associationTag(name string, foreignKey, references []string, opt options) string {
+	tags := make([]string, 0, 4)
+	if opt.JSONTag {
+		tags = append(tags, "json", toSnake(name)+",omitempty")
+	}
+	tags = append(tags, "gorm", "foreignKey:"+strings.Join(foreignKey, ",")+";references:"+strings.Join(references, ","))
+	return makeTagStr(tags)
+}
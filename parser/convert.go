@@ -0,0 +1,568 @@
+package parser
+
+import (
+	"go/format"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ConvertPB converts the models to and from the messages protoc-gen-go generates from the proto files of MakeProto.
+	ConvertPB = "pb"
+
+	// ConvertDTO converts the models to and from DTO structs with the same struct and field names,
+	// nullable fields are pointers, enums are string and sets are []string.
+	ConvertDTO = "dto"
+)
+
+var (
+	convertTmplRaw   string
+	convertTmpl      *template.Template
+	convertParseOnce sync.Once
+)
+
+// sqlNullTypes are the value field and type of the sql.NullXXX types.
+var sqlNullTypes = map[string][2]string{
+	"sql.NullInt32":   {"Int32", "int32"},
+	"sql.NullInt64":   {"Int64", "int64"},
+	"sql.NullFloat64": {"Float64", "float64"},
+	"sql.NullString":  {"String", "string"},
+	"sql.NullTime":    {"Time", "time.Time"},
+	"sql.NullBool":    {"Bool", "bool"},
+}
+
+// pbGoTypes are the go types protoc-gen-go uses for the proto scalar types.
+var pbGoTypes = map[string]string{
+	"int32":  "int32",
+	"int64":  "int64",
+	"uint32": "uint32",
+	"uint64": "uint64",
+	"bool":   "bool",
+	"float":  "float32",
+	"double": "float64",
+	"string": "string",
+	"bytes":  "[]byte",
+}
+
+// pqArrayTypes are the slice types of the postgres arrays.
+var pqArrayTypes = map[string]string{
+	"pq.Int64Array":   "[]int64",
+	"pq.Float64Array": "[]float64",
+	"pq.BoolArray":    "[]bool",
+	"pq.ByteaArray":   "[][]byte",
+	"pq.StringArray":  "[]string",
+}
+
+// convertImports are the import paths of the packages the conversions may use, by package name.
+var convertImports = map[string]string{
+	"sql":         "database/sql",
+	"fmt":         "fmt",
+	"strconv":     "strconv",
+	"decimal":     "github.com/shopspring/decimal",
+	"pq":          "github.com/lib/pq",
+	"wrapperspb":  "google.golang.org/protobuf/types/known/wrapperspb",
+	"timestamppb": "google.golang.org/protobuf/types/known/timestamppb",
+}
+
+// convertImportRes match the uses of the packages of convertImports, e.g. decimal.
+var convertImportRes = func() map[string]*regexp.Regexp {
+	res := make(map[string]*regexp.Regexp, len(convertImports))
+	for pkg := range convertImports {
+		res[pkg] = regexp.MustCompile(`\b` + pkg + `\.`)
+	}
+	return res
+}()
+
+// numericTypes .
+var numericTypes = map[string]bool{
+	"int": true, "int32": true, "int64": true, "uint": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true,
+}
+
+// convertData .
+type convertData struct {
+	Package   string        `json:"-"`
+	Imports   []string      `json:"-"`
+	ModelPkg  string        `json:"-"`
+	Model     string        `json:"-"`
+	TargetPkg string        `json:"-"`
+	Target    string        `json:"-"` // target struct name
+	Suffix    string        `json:"-"` // PB or DTO
+	FromErr   bool          `json:"-"` // From<Suffix> returns an error, a decimal string may not parse
+	To        []string      `json:"-"` // statements of To<Suffix>, m is the model and t the target
+	From      []string      `json:"-"` // statements of From<Suffix>
+	Enums     []convertEnum `json:"-"`
+}
+
+// convertEnum maps the values of a model enum to its proto enum and back.
+type convertEnum struct {
+	Name   string      `json:"-"` // lower camel name, the maps are <Name>ToPB and <Name>FromPB
+	Model  string      `json:"-"` // model type, string for the elements of a set
+	PB     string      `json:"-"` // proto enum type
+	Values [][2]string `json:"-"` // model constant, proto constant
+}
+
+// convertSide is how a field is stored on one side of a conversion.
+type convertSide struct {
+	Kind string // plain, sql, ptr, wrapper, timestamp or enum (a proto enum, 0 is NULL)
+	Base string // go type of the value, e.g. int64 for sql.NullInt64 and *int64
+	Type string // go type of the field, e.g. sql.NullInt64, or the wrapperspb constructor
+}
+
+// targetField is the field of the target struct a model field is converted to.
+type targetField struct {
+	Name string
+	Side convertSide
+	Set  bool // a proto repeated enum, converted element by element
+}
+
+// MakeConvert renders the To<Target>/From<Target> functions converting the model of table to the
+// target struct and back, see WithConvert. sql.NullXXX, pointers, wrappers, timestamps, decimals and
+// enums are converted, a field without a known conversion is left zero with a comment.
+func MakeConvert(table *Table, options ...Option) (string, error) {
+	opt := parseOption(options)
+	if opt.ModelImport == "" || opt.ConvertImport == "" {
+		return "", errors.New("model import and target import paths are required for converters")
+	}
+	if opt.ConvertTarget != ConvertPB && opt.ConvertTarget != ConvertDTO {
+		return "", errors.Errorf("unsupported convert target: %s", opt.ConvertTarget)
+	}
+	convertParseOnce.Do(func() {
+		convertTmpl = template.Must(template.New("goConvert").Parse(convertTmplRaw))
+	})
+
+	opt.Associations = false
+	model, _ := makeTmplData(table, opt)
+	data := convertData{
+		Package:   opt.ConvertPackage,
+		ModelPkg:  opt.Package,
+		Model:     model.TableName,
+		TargetPkg: opt.ConvertTarget,
+		Target:    model.TableName,
+		Suffix:    strings.ToUpper(opt.ConvertTarget),
+		FromErr:   opt.ConvertTarget == ConvertPB,
+	}
+
+	var targets []targetField
+	if opt.ConvertTarget == ConvertPB {
+		data.Target = goCamelCase(model.TableName)
+		targets = pbTargetFields(table, opt)
+		for _, enum := range model.Enums {
+			data.Enums = append(data.Enums, makeConvertEnum(enum, opt.Package))
+		}
+	} else {
+		targets = dtoTargetFields(table, opt)
+	}
+
+	enums := make(map[string]TmplEnum, len(model.Enums))
+	for _, enum := range model.Enums {
+		enums[enum.Column] = enum
+	}
+	for i, f := range model.Fields {
+		c := converter{
+			tmp:      toLowerCamel(f.Name) + "Value",
+			column:   f.ColumnName,
+			modelPkg: opt.Package,
+		}
+		if enum, ok := enums[f.ColumnName]; ok {
+			c.enum = &enum
+		}
+		ms := c.modelSide(f.GoType)
+		target := targets[i]
+
+		var to, from []string
+		var ok bool
+		if target.Set {
+			to, from, ok = c.pbSet("m."+f.Name, ms, "t."+target.Name)
+		} else if to, ok = c.field("m."+f.Name, ms, "t."+target.Name, target.Side, false); ok {
+			from, ok = c.field("t."+target.Name, target.Side, "m."+f.Name, ms, data.FromErr)
+		}
+		if !ok {
+			skip := "// " + f.Name + " is not converted, no conversion between " + f.GoType + " and " + target.Side.Type
+			data.To = append(data.To, skip)
+			data.From = append(data.From, skip)
+			continue
+		}
+		data.To = append(data.To, to...)
+		data.From = append(data.From, from...)
+	}
+
+	code := strings.Join(append(data.To, data.From...), "\n")
+	paths := make([]string, 0, len(convertImports))
+	for pkg, path := range convertImports {
+		if convertImportRes[pkg].MatchString(code) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	var std, third []string
+	for _, path := range paths {
+		if strings.Contains(path, ".") {
+			third = append(third, `"`+path+`"`)
+		} else {
+			std = append(std, `"`+path+`"`)
+		}
+	}
+	for _, group := range [][]string{std, third} {
+		if len(group) > 0 {
+			data.Imports = append(append(data.Imports, group...), "")
+		}
+	}
+	data.Imports = append(data.Imports, `"`+opt.ModelImport+`"`, data.TargetPkg+` "`+opt.ConvertImport+`"`)
+
+	builder := strings.Builder{}
+	if err := convertTmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	formatted, err := format.Source([]byte(builder.String()))
+	if err != nil {
+		return builder.String(), errors.WithMessage(err, "format golang code error")
+	}
+	return string(formatted), nil
+}
+
+// ParseConvertToWrite .
+func ParseConvertToWrite(table *Table, writer io.Writer, options ...Option) error {
+	code, err := MakeConvert(table, options...)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, code)
+	return err
+}
+
+// pbTargetFields are the message fields of the proto file MakeProto writes for table.
+func pbTargetFields(table *Table, opt options) []targetField {
+	_, fields := makeProtoFields(table, opt)
+	targets := make([]targetField, 0, len(fields))
+	for _, f := range fields {
+		target := targetField{Name: goCamelCase(f.Name), Side: convertSide{Kind: "plain", Type: f.Type}}
+		scalar, isScalar := pbGoTypes[f.Type]
+		switch {
+		case f.Repeated && f.Enum:
+			target.Set = true
+		case f.Repeated:
+			target.Side.Base = "[]" + scalar
+		case f.Enum:
+			target.Side = convertSide{Kind: "enum", Base: ConvertPB + "." + goCamelCase(f.Type), Type: f.Type}
+		case f.Type == "google.protobuf.Timestamp":
+			target.Side = convertSide{Kind: "timestamp", Base: "time.Time", Type: f.Type}
+		case strings.HasPrefix(f.Type, "google.protobuf."):
+			name := strings.TrimSuffix(strings.TrimPrefix(f.Type, "google.protobuf."), "Value")
+			target.Side = convertSide{Kind: "wrapper", Base: pbGoTypes[protoWrapperScalars[f.Type]], Type: "wrapperspb." + name}
+		case isScalar:
+			target.Side.Base = scalar
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// dtoTargetFields are the fields of the DTO struct: the model fields with the pointer null style,
+// enums are string and sets []string.
+func dtoTargetFields(table *Table, opt options) []targetField {
+	opt.NullStyle = NullInPointer
+	model, _ := makeTmplData(table, opt)
+	enums := make(map[string]TmplEnum, len(model.Enums))
+	for _, enum := range model.Enums {
+		enums[enum.Column] = enum
+	}
+
+	targets := make([]targetField, 0, len(model.Fields))
+	for _, f := range model.Fields {
+		target := targetField{Name: f.Name}
+		goType := f.GoType
+		if enum, ok := enums[f.ColumnName]; ok {
+			if enum.Set {
+				goType = "[]string"
+			} else {
+				goType = strings.Replace(goType, enum.Name, "string", 1)
+			}
+		} else if slice, ok := pqArrayTypes[goType]; ok {
+			goType = slice
+		}
+		if strings.HasPrefix(goType, "*") {
+			target.Side = convertSide{Kind: "ptr", Base: goType[1:], Type: goType}
+		} else {
+			target.Side = convertSide{Kind: "plain", Base: goType, Type: goType}
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// makeConvertEnum .
+func makeConvertEnum(enum TmplEnum, modelPkg string) convertEnum {
+	pbEnum := makeProtoEnum(enum)
+	e := convertEnum{
+		Name:  toLowerCamel(enum.Name),
+		Model: modelPkg + "." + enum.Name,
+		PB:    ConvertPB + "." + goCamelCase(enum.Name),
+	}
+	if enum.Set {
+		e.Model = "string"
+	}
+	for i, v := range enum.Values {
+		e.Values = append(e.Values, [2]string{modelPkg + "." + v.Name, e.PB + "_" + pbEnum.Values[i+1].Name})
+	}
+	return e
+}
+
+// converter writes the statements converting one field.
+type converter struct {
+	tmp      string // name of a temporary variable
+	column   string
+	modelPkg string
+	enum     *TmplEnum
+}
+
+// modelSide .
+func (c converter) modelSide(goType string) convertSide {
+	side := convertSide{Kind: "plain", Base: goType, Type: goType}
+	if null, ok := sqlNullTypes[goType]; ok {
+		side = convertSide{Kind: "sql", Base: null[1], Type: goType}
+	} else if strings.HasPrefix(goType, "*") {
+		side = convertSide{Kind: "ptr", Base: goType[1:], Type: goType}
+	}
+	if c.enum != nil && side.Base == c.enum.Name {
+		side.Base = c.modelPkg + "." + c.enum.Name
+	}
+	return side
+}
+
+// field returns the statements assigning src to dst, false if there is no conversion.
+// allowErr allows statements returning an error, only From functions return one.
+func (c converter) field(src string, from convertSide, dst string, to convertSide, allowErr bool) ([]string, bool) {
+	cond, value := "", src
+	switch from.Kind {
+	case "sql":
+		cond, value = src+".Valid", src+"."+sqlNullTypes[from.Type][0]
+	case "ptr":
+		cond, value = src+" != nil", "*"+src
+	case "wrapper":
+		cond, value = src+" != nil", src+".Value"
+	case "timestamp":
+		cond, value = src+" != nil", src+".AsTime()"
+	case "enum":
+		if to.Kind != "plain" {
+			cond = src + " != 0"
+		}
+	}
+
+	pre, expr, ok := c.convert(value, from.Base, to.Base, allowErr)
+	if !ok {
+		return nil, false
+	}
+	lines := pre
+	switch to.Kind {
+	case "sql":
+		lines = append(lines, dst+" = "+to.Type+"{"+sqlNullTypes[to.Type][0]+": "+expr+", Valid: true}")
+	case "ptr":
+		if expr != c.tmp {
+			lines = append(lines, c.tmp+" := "+expr)
+		}
+		lines = append(lines, dst+" = &"+c.tmp)
+	case "wrapper":
+		lines = append(lines, dst+" = "+to.Type+"("+expr+")")
+	case "timestamp":
+		lines = append(lines, dst+" = timestamppb.New("+expr+")")
+	default:
+		lines = append(lines, dst+" = "+expr)
+	}
+	if cond == "" {
+		return lines, true
+	}
+	return append(append([]string{"if " + cond + " {"}, lines...), "}"), true
+}
+
+// convert returns the expression turning value of type from into type to,
+// with the statements to run first when the conversion may fail.
+func (c converter) convert(value, from, to string, allowErr bool) (pre []string, expr string, ok bool) {
+	parse := func(call string) ([]string, string, bool) {
+		if !allowErr {
+			return nil, "", false
+		}
+		return []string{
+			c.tmp + ", err := " + call,
+			"if err != nil {",
+			`return nil, fmt.Errorf("` + c.column + `: %w", err)`,
+			"}",
+		}, c.tmp, true
+	}
+
+	// a method is called on the pointed value, not on the pointer
+	recv := value
+	if strings.HasPrefix(value, "*") {
+		recv = "(" + value + ")"
+	}
+	var enumType string
+	if c.enum != nil {
+		enumType = c.modelPkg + "." + c.enum.Name
+	}
+	switch {
+	case from == to:
+		return nil, value, true
+	case numericTypes[from] && numericTypes[to]:
+		return nil, to + "(" + value + ")", true
+	case from == "decimal.Decimal" && to == "string":
+		return nil, recv + ".String()", true
+	case from == "string" && to == "decimal.Decimal":
+		return parse("decimal.NewFromString(" + value + ")")
+	case from == "float64" && to == "string":
+		return nil, "strconv.FormatFloat(" + value + ", 'f', -1, 64)", true
+	case from == "string" && to == "float64":
+		return parse("strconv.ParseFloat(" + value + ", 64)")
+	case from == "float64" && to == "decimal.Decimal":
+		return nil, "decimal.NewFromFloat(" + value + ")", true
+	case from == "decimal.Decimal" && to == "float64":
+		return nil, recv + ".InexactFloat64()", true
+	case c.enum != nil && strings.HasPrefix(to, ConvertPB+"."):
+		if from == "string" {
+			value = enumType + "(" + value + ")"
+		}
+		return nil, toLowerCamel(c.enum.Name) + "ToPB[" + value + "]", true
+	case c.enum != nil && strings.HasPrefix(from, ConvertPB+"."):
+		expr = toLowerCamel(c.enum.Name) + "FromPB[" + value + "]"
+		if to == "string" {
+			expr = "string(" + expr + ")"
+		}
+		return nil, expr, true
+	case c.enum != nil && from == enumType && (to == "string" || to == "[]string"):
+		return nil, to + "(" + value + ")", true
+	case c.enum != nil && to == enumType && (from == "string" || from == "[]string"):
+		return nil, enumType + "(" + value + ")", true
+	case pqArrayTypes[from] == to, pqArrayTypes[to] == from:
+		return nil, to + "(" + value + ")", true
+	}
+	return nil, "", false
+}
+
+// pbSet converts a set column element by element to and from a repeated proto enum.
+func (c converter) pbSet(src string, model convertSide, dst string) (to, from []string, ok bool) {
+	if c.enum == nil || !c.enum.Set || (model.Kind != "plain" && model.Kind != "ptr") {
+		return nil, nil, false
+	}
+	name := toLowerCamel(c.enum.Name)
+	values := src
+	if model.Kind == "ptr" {
+		values = "*" + src
+		to = append(to, "if "+src+" != nil {")
+	}
+	to = append(to,
+		"for _, v := range "+values+" {",
+		dst+" = append("+dst+", "+name+"ToPB[v])",
+		"}")
+	if model.Kind == "ptr" {
+		to = append(to, "}")
+	}
+
+	from = []string{
+		"if " + dst + " != nil {",
+		c.tmp + " := make(" + model.Base + ", 0, len(" + dst + "))",
+		"for _, v := range " + dst + " {",
+		c.tmp + " = append(" + c.tmp + ", " + name + "FromPB[v])",
+		"}",
+	}
+	if model.Kind == "ptr" {
+		from = append(from, src+" = &"+c.tmp)
+	} else {
+		from = append(from, src+" = "+c.tmp)
+	}
+	return to, append(from, "}"), true
+}
+
+// goCamelCase is the go name protoc-gen-go gives to a proto name, e.g. user_id is UserId.
+func goCamelCase(s string) string {
+	isLower := func(c byte) bool { return 'a' <= c && c <= 'z' }
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isLower(s[i+1]):
+			// skipped, the next letter is upper cased
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+			// skipped, the next letter is upper cased
+		case '0' <= c && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func init() {
+	convertTmplRaw = `// Code generated by gmodel.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+// {{.Model}}To{{.Suffix}} converts {{.ModelPkg}}.{{.Model}} to {{.TargetPkg}}.{{.Target}}, nil for nil.
+func {{.Model}}To{{.Suffix}}(m *{{.ModelPkg}}.{{.Model}}) *{{.TargetPkg}}.{{.Target}} {
+	if m == nil {
+		return nil
+	}
+	t := &{{.TargetPkg}}.{{.Target}}{}
+{{- range .To}}
+	{{.}}
+{{- end}}
+	return t
+}
+
+// {{.Model}}From{{.Suffix}} converts {{.TargetPkg}}.{{.Target}} to {{.ModelPkg}}.{{.Model}}, nil for nil.
+{{- if .FromErr}}
+func {{.Model}}From{{.Suffix}}(t *{{.TargetPkg}}.{{.Target}}) (*{{.ModelPkg}}.{{.Model}}, error) {
+	if t == nil {
+		return nil, nil
+	}
+{{- else}}
+func {{.Model}}From{{.Suffix}}(t *{{.TargetPkg}}.{{.Target}}) *{{.ModelPkg}}.{{.Model}} {
+	if t == nil {
+		return nil
+	}
+{{- end}}
+	m := &{{.ModelPkg}}.{{.Model}}{}
+{{- range .From}}
+	{{.}}
+{{- end}}
+	return m{{if .FromErr}}, nil{{end}}
+}
+{{- range .Enums}}
+
+// {{.Name}}ToPB maps the values of {{.Model}} to {{.PB}}.
+var {{.Name}}ToPB = map[{{.Model}}]{{.PB}}{
+{{- range .Values}}
+	{{index . 0}}: {{index . 1}},
+{{- end}}
+}
+
+// {{.Name}}FromPB maps the values of {{.PB}} to {{.Model}}.
+var {{.Name}}FromPB = map[{{.PB}}]{{.Model}}{
+{{- range .Values}}
+	{{index . 1}}: {{index . 0}},
+{{- end}}
+}
+{{- end}}
+`
+}
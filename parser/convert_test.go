@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMakeConvert(t *testing.T) {
+	tables, err := GetTablesFromSQL("CREATE TABLE `orders` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` int NULL,\n" +
+		"  `status` enum('new','paid') NULL,\n" +
+		"  `tags` set('gift','urgent') NULL,\n" +
+		"  `amount` decimal(10,2) NOT NULL,\n" +
+		"  `paid_at` datetime NULL,\n" +
+		"  `meta` json NOT NULL,\n" +
+		"  `note` varchar(64) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := []Option{WithModelImport("example.com/app/model"),
+		WithTypeMapping(TypeMapping{Column: "orders.meta", GoType: "datatypes.JSON", Import: "gorm.io/datatypes"})}
+
+	pb, err := MakeConvert(tables[0], append(options, WithConvert(ConvertPB, "example.com/app/pb"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"package convert\n",
+		"\t\"database/sql\"\n\t\"fmt\"\n\n\t\"github.com/shopspring/decimal\"\n",
+		"\t\"example.com/app/model\"\n\tpb \"example.com/app/pb\"\n",
+		"func OrdersToPB(m *model.Orders) *pb.Orders {",
+		"\tif m.UserID.Valid {\n\t\tt.UserId = wrapperspb.Int32(m.UserID.Int32)\n\t}\n",
+		"\tif m.Status.Valid {\n\t\tt.Status = orderStatusToPB[model.OrderStatus(m.Status.String)]\n\t}\n",
+		"\tfor _, v := range m.Tags {\n\t\tt.Tags = append(t.Tags, orderTagsToPB[v])\n\t}\n",
+		"\tt.Amount = m.Amount.String()\n",
+		"\tif m.PaIDAt.Valid {\n\t\tt.PaidAt = timestamppb.New(m.PaIDAt.Time)\n\t}\n",
+		"\t// Meta is not converted, no conversion between datatypes.JSON and string\n",
+		// NULL is not declared, the model field and the message field are plain strings
+		"\tt.Note = m.Note\n",
+		"\tm.Note = t.Note\n",
+		"\tif t.UserId != nil {\n\t\tm.UserID = sql.NullInt32{Int32: t.UserId.Value, Valid: true}\n\t}\n",
+		"func OrdersFromPB(t *pb.Orders) (*model.Orders, error) {",
+		"\tif t.Status != 0 {\n\t\tm.Status = sql.NullString{String: string(orderStatusFromPB[t.Status]), Valid: true}\n\t}\n",
+		"\tamountValue, err := decimal.NewFromString(t.Amount)\n\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"amount: %w\", err)\n\t}\n\tm.Amount = amountValue\n",
+		"var orderStatusToPB = map[model.OrderStatus]pb.OrderStatus{\n\tmodel.OrderStatusNew:  pb.OrderStatus_ORDER_STATUS_NEW,\n",
+		"var orderTagsFromPB = map[pb.OrderTags]string{\n\tpb.OrderTags_ORDER_TAGS_GIFT:   model.OrderTagsGift,\n",
+	} {
+		if !strings.Contains(pb, s) {
+			t.Errorf("pb converter should contain %q:\n%s", s, pb)
+		}
+	}
+
+	// without null types the messages have no wrappers either
+	plain, err := MakeConvert(tables[0], append(options, WithNoNullType(), WithConvert(ConvertPB, "example.com/app/pb"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(plain, "\tt.UserId = int32(m.UserID)\n") || strings.Contains(plain, "wrapperspb") {
+		t.Errorf("pb converter without null types should not use wrappers:\n%s", plain)
+	}
+
+	dto, err := MakeConvert(tables[0], append(options, WithNullStyle(NullInPointer),
+		WithConvert(ConvertDTO, "example.com/app/dto"), WithConvertPackage("mapper"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"package mapper\n",
+		"func OrdersToDTO(m *model.Orders) *dto.Orders {",
+		"\tif m.Status != nil {\n\t\tstatusValue := string(*m.Status)\n\t\tt.Status = &statusValue\n\t}\n",
+		"\tif m.Tags != nil {\n\t\tt.Tags = []string(*m.Tags)\n\t}\n",
+		"\tt.Meta = m.Meta\n",
+		"func OrdersFromDTO(t *dto.Orders) *model.Orders {",
+		"\tif t.Status != nil {\n\t\tstatusValue := model.OrderStatus(*t.Status)\n\t\tm.Status = &statusValue\n\t}\n",
+	} {
+		if !strings.Contains(dto, s) {
+			t.Errorf("dto converter should contain %q:\n%s", s, dto)
+		}
+	}
+
+	if _, err := MakeConvert(tables[0], WithModelImport("example.com/app/model")); err == nil {
+		t.Error("a converter without target should fail")
+	}
+}
+
+func TestGoCamelCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"user_id":   "UserId",
+		"paid_at":   "PaidAt",
+		"ip_v4":     "IpV4",
+		"log2_size": "Log2Size",
+		"_private":  "XPrivate",
+		"Orders":    "Orders",
+	} {
+		if got := goCamelCase(name); got != want {
+			t.Errorf("goCamelCase(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
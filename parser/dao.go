@@ -0,0 +1,272 @@
+package parser
+
+import (
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	daoTmplRaw   string
+	daoTmpl      *template.Template
+	daoParseOnce sync.Once
+)
+
+// daoData .
+type daoData struct {
+	Package     string   `json:"-"`
+	ModelPkg    string   `json:"-"`
+	ModelImport string   `json:"-"`
+	Imports     []string `json:"-"`
+	Model       string   `json:"-"`
+	Repo        string   `json:"-"`
+	View        bool     `json:"-"` // a view has no write methods
+	PrimaryKey  *daoKey  `json:"-"`
+	Finders     []daoKey `json:"-"`
+}
+
+// daoKey is a set of columns identifying a row, the primary key or a unique index.
+type daoKey struct {
+	Method string `json:"-"` // e.g. ByID, ByUserIDAndRoleID
+	Params string `json:"-"` // e.g. userID int, roleID int
+	Where  string `json:"-"` // e.g. map[string]interface{}{"user_id": userID, "role_id": roleID}
+}
+
+// MakeDao renders the repository code of table, the model package is imported from WithModelImport.
+func MakeDao(table *Table, options ...Option) (string, error) {
+	opt := parseOption(options)
+	if opt.ModelImport == "" {
+		return "", errors.New("model import path is required for dao")
+	}
+	daoParseOnce.Do(func() {
+		daoTmpl = template.Must(template.New("goDao").Parse(daoTmplRaw))
+	})
+
+	model, _ := makeTmplData(table, opt)
+	data := daoData{
+		Package:     opt.DaoPackage,
+		ModelPkg:    opt.Package,
+		ModelImport: opt.ModelImport,
+		Model:       model.TableName,
+		Repo:        model.TableName + "Repo",
+		View:        table.View,
+	}
+
+	// enum types are declared in the model package
+	enums := make(map[string]bool, len(model.Enums))
+	for _, enum := range model.Enums {
+		enums[enum.Name] = true
+	}
+	for i, f := range model.Fields {
+		if name := strings.TrimPrefix(f.GoType, "*"); enums[name] {
+			model.Fields[i].GoType = f.GoType[:len(f.GoType)-len(name)] + opt.Package + "." + name
+		}
+	}
+
+	fields := make(map[string]TmplField, len(model.Fields))
+	var pk []TmplField
+	for _, f := range model.Fields {
+		fields[f.ColumnName] = f
+		if f.PrimaryKey {
+			pk = append(pk, f)
+		}
+	}
+	// only the types of key params are referenced in dao code
+	paths := make(map[string]struct{})
+	addPaths := func(cols []TmplField) {
+		for _, f := range cols {
+			if f.ImportPath != "" {
+				paths[f.ImportPath] = struct{}{}
+			}
+		}
+	}
+	if len(pk) > 0 {
+		key := makeDaoKey(pk, opt.Package)
+		data.PrimaryKey = &key
+		addPaths(pk)
+	}
+
+	// unique columns first, then unique indexes; keys equal to the primary key are skipped
+	seen := map[string]bool{}
+	if data.PrimaryKey != nil {
+		seen[data.PrimaryKey.Method] = true
+	}
+	addFinder := func(cols []TmplField) {
+		key := makeDaoKey(cols, opt.Package)
+		if !seen[key.Method] {
+			seen[key.Method] = true
+			data.Finders = append(data.Finders, key)
+			addPaths(cols)
+		}
+	}
+	for _, f := range model.Fields {
+		if f.Unique {
+			addFinder([]TmplField{f})
+		}
+	}
+	for _, index := range table.Indexes {
+		if !index.Unique {
+			continue
+		}
+		cols := make([]TmplField, 0, len(index.Columns))
+		for _, name := range index.Columns {
+			if f, ok := fields[name]; ok {
+				cols = append(cols, f)
+			}
+		}
+		if len(cols) == len(index.Columns) {
+			addFinder(cols)
+		}
+	}
+
+	for path := range paths {
+		data.Imports = append(data.Imports, path)
+	}
+	sort.Strings(data.Imports)
+
+	builder := strings.Builder{}
+	if err := daoTmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	code, err := format.Source([]byte(builder.String()))
+	if err != nil {
+		return builder.String(), errors.WithMessage(err, "format golang code error")
+	}
+	return string(code), nil
+}
+
+// ParseDaoToWrite .
+func ParseDaoToWrite(table *Table, writer io.Writer, options ...Option) error {
+	code, err := MakeDao(table, options...)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, code)
+	return err
+}
+
+// makeDaoKey .
+func makeDaoKey(cols []TmplField, modelPkg string) daoKey {
+	names := make([]string, 0, len(cols))
+	params := make([]string, 0, len(cols))
+	where := make([]string, 0, len(cols))
+	for _, f := range cols {
+		arg := paramName(f.Name, modelPkg)
+		names = append(names, f.Name)
+		params = append(params, arg+" "+f.GoType)
+		where = append(where, `"`+f.ColumnName+`": `+arg)
+	}
+	return daoKey{
+		Method: "By" + strings.Join(names, "And"),
+		Params: strings.Join(params, ", "),
+		Where:  "map[string]interface{}{" + strings.Join(where, ", ") + "}",
+	}
+}
+
+// daoNames are the variables and packages used in dao code, a parameter must not shadow them.
+var daoNames = map[string]bool{
+	"ctx":     true,
+	"r":       true,
+	"m":       true,
+	"ms":      true,
+	"db":      true,
+	"err":     true,
+	"context": true,
+	"gorm":    true,
+	"model":   true,
+}
+
+// paramName turns a go field name into a parameter name which doesn't shadow the names used in dao code
+// or the model package modelPkg.
+func paramName(field, modelPkg string) string {
+	name := toLowerCamel(field)
+	if token.IsKeyword(name) || daoNames[name] || name == modelPkg {
+		return name + "Arg"
+	}
+	return name
+}
+
+func init() {
+	daoTmplRaw = `// Code generated by gmodel.
+
+package {{.Package}}
+
+import (
+	"context"
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+
+	"gorm.io/gorm"
+
+	"{{.ModelImport}}"
+)
+
+// {{.Repo}} is the repository of {{.ModelPkg}}.{{.Model}}.{{if .View}} It is read-only, {{.Model}} maps a view.{{end}}
+type {{.Repo}} struct {
+	db *gorm.DB
+}
+
+// New{{.Repo}} .
+func New{{.Repo}}(db *gorm.DB) *{{.Repo}} {
+	return &{{.Repo}}{db: db}
+}
+
+{{- if not .View}}
+// Create .
+func (r *{{.Repo}}) Create(ctx context.Context, m *{{.ModelPkg}}.{{.Model}}) error {
+	return r.db.WithContext(ctx).Create(m).Error
+}
+{{end}}
+{{- with .PrimaryKey}}
+// Get{{.Method}} .
+func (r *{{$.Repo}}) Get{{.Method}}(ctx context.Context, {{.Params}}) (*{{$.ModelPkg}}.{{$.Model}}, error) {
+	m := &{{$.ModelPkg}}.{{$.Model}}{}
+	if err := r.db.WithContext(ctx).Where({{.Where}}).First(m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{- if not $.View}}
+
+// Update saves all fields of m by its primary key.
+func (r *{{$.Repo}}) Update(ctx context.Context, m *{{$.ModelPkg}}.{{$.Model}}) error {
+	return r.db.WithContext(ctx).Select("*").Updates(m).Error
+}
+
+// Delete .
+func (r *{{$.Repo}}) Delete(ctx context.Context, {{.Params}}) error {
+	return r.db.WithContext(ctx).Where({{.Where}}).Delete(&{{$.ModelPkg}}.{{$.Model}}{}).Error
+}
+{{- end}}
+{{end}}
+{{- range .Finders}}
+// Find{{.Method}} .
+func (r *{{$.Repo}}) Find{{.Method}}(ctx context.Context, {{.Params}}) (*{{$.ModelPkg}}.{{$.Model}}, error) {
+	m := &{{$.ModelPkg}}.{{$.Model}}{}
+	if err := r.db.WithContext(ctx).Where({{.Where}}).First(m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+{{end}}
+// List returns at most limit rows from offset, limit <= 0 means no limit.
+func (r *{{.Repo}}) List(ctx context.Context, offset, limit int) ([]*{{.ModelPkg}}.{{.Model}}, error) {
+	var ms []*{{.ModelPkg}}.{{.Model}}
+	db := r.db.WithContext(ctx).Offset(offset)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if err := db.Find(&ms).Error; err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+`
+}
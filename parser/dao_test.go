@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMakeDao(t *testing.T) {
+	tables, err := GetTablesFromSQL("CREATE TABLE `tbl_users` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `email` varchar(128) NOT NULL,\n" +
+		"  `type` varchar(10) NOT NULL,\n" +
+		"  `created_at` datetime NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_email` (`email`),\n" +
+		"  UNIQUE KEY `uk_type_created` (`type`, `created_at`),\n" +
+		"  KEY `idx_created` (`created_at`)\n" +
+		");")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = MakeDao(tables[0]); err == nil {
+		t.Error("dao without model import should fail")
+	}
+
+	code, err := MakeDao(tables[0], WithTablePrefix("tbl_"), WithPackage("internal"),
+		WithDaoPackage("repo"), WithModelImport("example.com/app/dao/internal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"package repo",
+		`"example.com/app/dao/internal"`,
+		`"time"`,
+		"func NewUsersRepo(db *gorm.DB) *UsersRepo",
+		"func (r *UsersRepo) GetByID(ctx context.Context, id int64) (*internal.Users, error)",
+		"func (r *UsersRepo) Delete(ctx context.Context, id int64) error",
+		"func (r *UsersRepo) FindByEmail(ctx context.Context, email string) (*internal.Users, error)",
+		"func (r *UsersRepo) FindByTypeAndCreatedAt(ctx context.Context, typeArg string, createdAt time.Time) (*internal.Users, error)",
+		`Where(map[string]interface{}{"type": typeArg, "created_at": createdAt})`,
+		"func (r *UsersRepo) List(ctx context.Context, offset, limit int) ([]*internal.Users, error)",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("%q not found in:\n%s", s, code)
+		}
+	}
+	if strings.Contains(code, "FindByCreatedAt") {
+		t.Errorf("non unique index should not have a finder:\n%s", code)
+	}
+}
+
+func TestMakeDaoParamNames(t *testing.T) {
+	tables, err := GetTablesFromSQL("CREATE TABLE `devices` (\n" +
+		"  `model` varchar(32) NOT NULL,\n" +
+		"  `gorm` int NOT NULL,\n" +
+		"  `context` varchar(32) NOT NULL,\n" +
+		"  `internal` int NOT NULL,\n" +
+		"  PRIMARY KEY (`model`, `gorm`),\n" +
+		"  UNIQUE KEY `uk_context_internal` (`context`, `internal`)\n" +
+		");")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range []string{"model", "internal"} {
+		code, err := MakeDao(tables[0], WithPackage(pkg), WithModelImport("example.com/app/"+pkg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{
+			"GetByModelAndGorm(ctx context.Context, modelArg string, gormArg int)",
+			"FindByContextAndInternal(ctx context.Context, contextArg string, internal",
+		} {
+			if !strings.Contains(code, s) {
+				t.Errorf("%q not found in:\n%s", s, code)
+			}
+		}
+		if pkg == "internal" && !strings.Contains(code, "internalArg int)") {
+			t.Errorf("the model package name should be reserved:\n%s", code)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// intWidthRe matches the display width of integer types, which mysql 8 no longer prints.
+var intWidthRe = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|integer|bigint)\(\d+\)`)
+
+// DiffModel compares model with table read from the database and returns one line per drift,
+// "+" is only in the database, "-" is only in the model and "~" differs (model -> database).
+// Without a type in the gorm tag the field type is compared with the one gmodel generates with options.
+func DiffModel(model *ModelStruct, table *Table, options ...Option) []string {
+	opt := parseOption(options)
+	data, _ := makeTmplData(table, opt)
+	goTypes := make(map[string]string, len(data.Fields))
+	for _, f := range data.Fields {
+		goTypes[f.ColumnName] = f.GoType
+	}
+
+	var diff []string
+	mt := model.Table
+	mUnique, dbUnique := uniqueColumns(mt), uniqueColumns(table)
+	for _, col := range table.Columns {
+		i := mt.column(col.Name)
+		if i < 0 {
+			diff = append(diff, "+ column "+col.Name+" "+columnDefinition(col))
+			continue
+		}
+		m := mt.Columns[i]
+		if m.SQLType != "" {
+			if normalizeSQLType(m.SQLType) != normalizeSQLType(col.SQLType) {
+				diff = append(diff, fmt.Sprintf("~ column %s: type %s -> %s", col.Name, m.SQLType, col.SQLType))
+			}
+		} else if goType := model.GoTypes[m.Name]; goType != goTypes[col.Name] {
+			diff = append(diff, fmt.Sprintf("~ column %s: go type %s -> %s", col.Name, goType, goTypes[col.Name]))
+		}
+		if m.NotNull != (col.NotNull || col.PrimaryKey) {
+			diff = append(diff, fmt.Sprintf("~ column %s: %s -> %s", col.Name, nullString(m.NotNull), nullString(col.NotNull || col.PrimaryKey)))
+		}
+		if mUnique[strings.ToLower(col.Name)] != dbUnique[strings.ToLower(col.Name)] {
+			diff = append(diff, fmt.Sprintf("~ column %s: %s -> %s", col.Name,
+				uniqueString(mUnique[strings.ToLower(col.Name)]), uniqueString(dbUnique[strings.ToLower(col.Name)])))
+		}
+	}
+	for _, m := range mt.Columns {
+		if table.column(m.Name) < 0 {
+			diff = append(diff, "- column "+m.Name)
+		}
+	}
+
+	if mPK, dbPK := primaryKeyColumns(mt), primaryKeyColumns(table); !strings.EqualFold(mPK, dbPK) {
+		diff = append(diff, fmt.Sprintf("~ primary key (%s) -> (%s)", mPK, dbPK))
+	}
+
+	// single column unique indexes are compared as unique columns above
+	for _, index := range table.Indexes {
+		if isUniqueColumnIndex(index) {
+			continue
+		}
+		i := mt.index(index.Name)
+		if i < 0 {
+			diff = append(diff, "+ "+indexString(index))
+			continue
+		}
+		if m := mt.Indexes[i]; indexString(m) != indexString(index) {
+			diff = append(diff, fmt.Sprintf("~ %s -> %s", indexString(m), indexString(index)))
+		}
+	}
+	for _, index := range mt.Indexes {
+		if !isUniqueColumnIndex(index) && table.index(index.Name) < 0 {
+			diff = append(diff, "- "+indexString(index))
+		}
+	}
+	return diff
+}
+
+// normalizeSQLType .
+func normalizeSQLType(sqlType string) string {
+	sqlType = strings.Join(strings.Fields(strings.ToLower(sqlType)), " ")
+	return intWidthRe.ReplaceAllString(sqlType, "$1")
+}
+
+// columnDefinition .
+func columnDefinition(col *Column) string {
+	return col.SQLType + " " + nullString(col.NotNull || col.PrimaryKey)
+}
+
+// nullString .
+func nullString(notNull bool) string {
+	if notNull {
+		return "NOT NULL"
+	}
+	return "NULL"
+}
+
+// uniqueString .
+func uniqueString(unique bool) string {
+	if unique {
+		return "unique"
+	}
+	return "not unique"
+}
+
+// uniqueColumns returns the lower case names of the columns which are unique on their own.
+func uniqueColumns(t *Table) map[string]bool {
+	unique := make(map[string]bool)
+	for _, col := range t.Columns {
+		if col.Unique {
+			unique[strings.ToLower(col.Name)] = true
+		}
+	}
+	for _, index := range t.Indexes {
+		if isUniqueColumnIndex(index) {
+			unique[strings.ToLower(index.Columns[0])] = true
+		}
+	}
+	return unique
+}
+
+// isUniqueColumnIndex .
+func isUniqueColumnIndex(index *Index) bool {
+	return index.Unique && len(index.Columns) == 1
+}
+
+// primaryKeyColumns .
+func primaryKeyColumns(t *Table) string {
+	var cols []string
+	for _, col := range t.Columns {
+		if col.PrimaryKey {
+			cols = append(cols, col.Name)
+		}
+	}
+	return strings.Join(cols, ", ")
+}
+
+// indexString .
+func indexString(index *Index) string {
+	kind := "index"
+	switch {
+	case index.Unique:
+		kind = "unique index"
+	case index.Fulltext:
+		kind = "fulltext index"
+	}
+	return kind + " " + index.Name + " (" + strings.Join(index.Columns, ", ") + ")"
+}
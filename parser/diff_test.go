@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffModel(t *testing.T) {
+	const oldDDL = "CREATE TABLE `tbl_orders` (\n" +
+		"  `id` bigint(20) NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` int(11) NOT NULL,\n" +
+		"  `amount` decimal(10,2) NOT NULL,\n" +
+		"  `note` varchar(64) NOT NULL,\n" +
+		"  `legacy` int(11) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_note` (`note`),\n" +
+		"  KEY `idx_user` (`user_id`, `amount`)\n" +
+		");"
+	const newDDL = "CREATE TABLE `tbl_orders` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` bigint NOT NULL,\n" +
+		"  `amount` decimal(12,2) NOT NULL,\n" +
+		"  `note` varchar(64) NULL,\n" +
+		"  `status` tinyint NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `idx_user` (`user_id`),\n" +
+		"  KEY `idx_status` (`status`)\n" +
+		");"
+
+	for _, withType := range []bool{true, false} {
+		options := []Option{WithTablePrefix("tbl_"), WithJSONTag()}
+		if withType {
+			options = append(options, WithGormType())
+		}
+		buf := &bytes.Buffer{}
+		if err := ParseSQLToWrite(oldDDL, buf, options...); err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "orders.go"), buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		models, err := ParseModelDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(models) != 1 || models[0].Name != "Orders" || models[0].Table.Name != "tbl_orders" {
+			t.Fatalf("unexpected models: %+v", models)
+		}
+
+		oldTables, _ := GetTablesFromSQL(oldDDL)
+		if diff := DiffModel(models[0], oldTables[0], options...); len(diff) != 0 {
+			t.Errorf("unexpected drift of the same schema: %v", diff)
+		}
+
+		newTables, _ := GetTablesFromSQL(newDDL)
+		// the parser prints the default display width of integer types
+		want := []string{
+			"~ column user_id: type int(11) -> bigint(20)",
+			"~ column amount: type decimal(10,2) -> decimal(12,2)",
+			"~ column note: NOT NULL -> NULL",
+			"~ column note: unique -> not unique",
+			"+ column status tinyint(4) NOT NULL",
+			"- column legacy",
+			"~ index idx_user (user_id, amount) -> index idx_user (user_id)",
+			"+ index idx_status (status)",
+		}
+		if !withType {
+			want = append([]string{
+				"~ column user_id: go type int -> int64",
+				"~ column note: go type string -> sql.NullString",
+			}, want[2:]...)
+		}
+		if got := DiffModel(models[0], newTables[0], options...); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("with type %v, unexpected drift:\n%s\nwant:\n%s", withType, strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Driver is the kind of database the schema is read from.
+type Driver string
+
+const (
+	// DriverMySQL .
+	DriverMySQL Driver = "mysql"
+
+	// DriverPostgres .
+	DriverPostgres Driver = "postgres"
+
+	// DriverSQLite .
+	DriverSQLite Driver = "sqlite"
+)
+
+// ParseDriver checks the driver name given by flag or config, empty name means detect it from dsn.
+func ParseDriver(name, dsn string) (Driver, error) {
+	switch strings.ToLower(name) {
+	case "":
+		return DetectDriver(dsn), nil
+	case "mysql":
+		return DriverMySQL, nil
+	case "postgres", "postgresql", "pg":
+		return DriverPostgres, nil
+	case "sqlite", "sqlite3":
+		return DriverSQLite, nil
+	default:
+		return "", errors.Errorf("unsupported driver: %s", name)
+	}
+}
+
+// pqKeyValueRe matches the lib/pq key=value dsn, host=localhost user=app dbname=app.
+var pqKeyValueRe = regexp.MustCompile(`(^|\s)(host|hostaddr|dbname)\s*=`)
+
+// DetectDriver picks the driver from the dsn scheme, the lib/pq key=value form or the sqlite file extension,
+// mysql if there is no known one.
+func DetectDriver(dsn string) Driver {
+	lower := strings.ToLower(dsn)
+	keyValue := pqKeyValueRe.MatchString(lower)
+	if i := strings.Index(lower, "?"); i >= 0 {
+		lower = lower[:i]
+	}
+	switch {
+	case strings.HasPrefix(lower, "postgres://"), strings.HasPrefix(lower, "postgresql://"), keyValue:
+		return DriverPostgres
+	case strings.HasPrefix(lower, "sqlite://"), strings.HasPrefix(lower, "sqlite3://"), strings.HasPrefix(lower, "file:"),
+		strings.HasSuffix(lower, ".db"), strings.HasSuffix(lower, ".sqlite"), strings.HasSuffix(lower, ".sqlite3"):
+		return DriverSQLite
+	default:
+		return DriverMySQL
+	}
+}
+
+// GetTables 获取待生成model的所有表, table 可为表名、"*" 或逗号分隔的 glob / /regexp/
+func GetTables(driver Driver, dsn, table string) ([]string, error) {
+	filter, err := NewTableFilter([]string{table}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := filter.Name(); ok {
+		return []string{name}, nil
+	}
+
+	var names []string
+	switch driver {
+	case DriverPostgres:
+		names, err = getPostgresTables(dsn)
+	case DriverSQLite:
+		names, err = getSQLiteTables(dsn)
+	default:
+		names, err = getCreateTablesByConfig(dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return filter.Filter(names), nil
+}
+
+// GetTableFromDB reads the definition of tableName through the driver,
+// options are used to parse the mysql DDL (charset, collation).
+func GetTableFromDB(driver Driver, dsn, tableName string, options ...Option) (*Table, error) {
+	switch driver {
+	case DriverPostgres:
+		return GetTableFromPostgres(dsn, tableName)
+	case DriverSQLite:
+		return GetTableFromSQLite(dsn, tableName)
+	default:
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			return nil, errors.WithMessage(err, "open db error")
+		}
+		defer db.Close()
+		return mysqlTable(context.Background(), db, tableName, options...)
+	}
+}
+
+// ParseTableFromDB .
+func ParseTableFromDB(driver Driver, dsn, tableName string, options ...Option) (ModelCodes, error) {
+	table, err := GetTableFromDB(driver, dsn, tableName, options...)
+	if err != nil {
+		return ModelCodes{}, err
+	}
+	return ParseTables([]*Table{table}, options...)
+}
+
+// ParseTableFromDBToWrite .
+func ParseTableFromDBToWrite(driver Driver, dsn, tableName string, writer io.Writer, options ...Option) error {
+	data, err := ParseTableFromDB(driver, dsn, tableName, options...)
+	if err != nil {
+		return err
+	}
+	return writeModelCodes(data, writer, options...)
+}
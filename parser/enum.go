@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/mysql"
+	"github.com/jinzhu/inflection"
+)
+
+// TmplEnum is the named type of an enum or set column.
+type TmplEnum struct {
+	Name   string          `json:"-"` // go type name, e.g. OrderStatus
+	Column string          `json:"-"`
+	Set    bool            `json:"-"` // a set is a []string with Scan/Value, an enum is a string
+	Values []TmplEnumValue `json:"-"`
+}
+
+// TmplEnumValue is a constant of TmplEnum.
+type TmplEnumValue struct {
+	Name  string `json:"-"` // go constant name, e.g. OrderStatusPaid
+	Value string `json:"-"`
+}
+
+// makeEnum returns the named type of a mysql enum/set column, nil for other columns.
+// The type is named after the singular struct name and the field: orders.status is OrderStatus.
+// A name taken by a struct of the schema gets the Type suffix, users.roles is UserRolesType next to user_roles.
+func makeEnum(structName, field string, col *Column, structs map[string]bool) *TmplEnum {
+	if col.Tp == nil || (col.Tp.Tp != mysql.TypeEnum && col.Tp.Tp != mysql.TypeSet) || len(col.Tp.Elems) == 0 {
+		return nil
+	}
+	name := field
+	if prefix := inflection.Singular(structName); !strings.HasPrefix(field, prefix) {
+		name = prefix + field
+	}
+	for base, i := name, 0; structs[name]; i++ {
+		name = base + "Type"
+		if i > 0 {
+			name += strconv.Itoa(i)
+		}
+	}
+	enum := &TmplEnum{Name: name, Column: col.Name, Set: col.Tp.Tp == mysql.TypeSet}
+
+	used := make(map[string]bool, len(col.Tp.Elems))
+	for i, elem := range col.Tp.Elems {
+		suffix := toCamel(elem)
+		if suffix == "" {
+			suffix = "Empty"
+		}
+		constName := name + suffix
+		if used[constName] {
+			constName += strconv.Itoa(i)
+		}
+		used[constName] = true
+		enum.Values = append(enum.Values, TmplEnumValue{Name: constName, Value: elem})
+	}
+	return enum
+}
+
+// goType is the field type of the enum column, sql.NullString for a nullable enum with NullInSQL
+// since the named type can't hold NULL, a set scans NULL as nil.
+func (e *TmplEnum) goType(style NullStyle) (name, path string) {
+	switch {
+	case style == NullInSQL && !e.Set:
+		return "sql.NullString", "database/sql"
+	case style == NullInPointer:
+		return "*" + e.Name, ""
+	default:
+		return e.Name, ""
+	}
+}
+
+// importPath returns the packages the declaration of e needs.
+func (e *TmplEnum) importPath() []string {
+	if e.Set {
+		return []string{"database/sql/driver", "fmt", "strings"}
+	}
+	return nil
+}
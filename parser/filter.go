@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TableFilter selects tables by name.
+// A pattern is a table name, a glob such as user_* or a regexp between slashes such as /^log_\d+$/,
+// several patterns are separated by commas.
+type TableFilter struct {
+	all     bool
+	name    string // the only include pattern when it is a plain table name
+	include []func(string) bool
+	exclude []func(string) bool
+}
+
+// NewTableFilter builds a filter from the include and exclude patterns,
+// no include pattern or "*" includes all tables.
+func NewTableFilter(include, exclude []string) (*TableFilter, error) {
+	f := &TableFilter{}
+	includes := splitPatterns(include)
+	if len(includes) == 0 {
+		f.all = true
+	}
+	for _, pattern := range includes {
+		if pattern == "*" {
+			f.all = true
+			continue
+		}
+		match, err := tableMatcher(pattern)
+		if err != nil {
+			return nil, err
+		}
+		f.include = append(f.include, match)
+	}
+	if len(includes) == 1 && !f.all && !isTablePattern(includes[0]) {
+		f.name = includes[0]
+	}
+
+	for _, pattern := range splitPatterns(exclude) {
+		match, err := tableMatcher(pattern)
+		if err != nil {
+			return nil, err
+		}
+		f.exclude = append(f.exclude, match)
+	}
+	return f, nil
+}
+
+// Name returns the table name when the filter selects a single table by its name.
+func (f *TableFilter) Name() (string, bool) {
+	return f.name, f.name != "" && len(f.exclude) == 0
+}
+
+// Match reports whether the table is included and not excluded.
+func (f *TableFilter) Match(name string) bool {
+	return f.Included(name) && !f.Excluded(name)
+}
+
+// Included reports whether the table matches an include pattern.
+func (f *TableFilter) Included(name string) bool {
+	if f.all {
+		return true
+	}
+	for _, match := range f.include {
+		if match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded reports whether the table matches an exclude pattern.
+func (f *TableFilter) Excluded(name string) bool {
+	for _, match := range f.exclude {
+		if match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the names matched by the filter, in their order.
+func (f *TableFilter) Filter(names []string) []string {
+	matched := make([]string, 0, len(names))
+	for _, name := range names {
+		if f.Match(name) {
+			matched = append(matched, name)
+		}
+	}
+	return matched
+}
+
+// isTablePattern .
+func isTablePattern(pattern string) bool {
+	return isRegexpPattern(pattern) || strings.ContainsAny(pattern, "*?[")
+}
+
+// isRegexpPattern .
+func isRegexpPattern(pattern string) bool {
+	return len(pattern) >= 2 && pattern[0] == '/' && pattern[len(pattern)-1] == '/'
+}
+
+// tableMatcher .
+func tableMatcher(pattern string) (func(string) bool, error) {
+	switch {
+	case isRegexpPattern(pattern):
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid table pattern %s", pattern)
+		}
+		return re.MatchString, nil
+	case isTablePattern(pattern):
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.WithMessagef(err, "invalid table pattern %s", pattern)
+		}
+		return func(name string) bool {
+			ok, _ := path.Match(pattern, name)
+			return ok
+		}, nil
+	default:
+		return func(name string) bool { return name == pattern }, nil
+	}
+}
+
+// splitPatterns splits the comma separated patterns, commas inside a /regexp/ are kept
+// even if the regexp was split into several list items (pflag string slices split on commas).
+func splitPatterns(lists []string) []string {
+	var patterns []string
+	var regexpPart string
+	for _, part := range strings.Split(strings.Join(lists, ","), ",") {
+		if regexpPart != "" {
+			regexpPart += "," + part
+			if strings.HasSuffix(part, "/") {
+				patterns = append(patterns, regexpPart)
+				regexpPart = ""
+			}
+			continue
+		}
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "/") && !isRegexpPattern(part) {
+			regexpPart = part
+			continue
+		}
+		if part != "" {
+			patterns = append(patterns, part)
+		}
+	}
+	if regexpPart != "" {
+		patterns = append(patterns, regexpPart)
+	}
+	return patterns
+}
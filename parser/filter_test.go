@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableFilter(t *testing.T) {
+	names := []string{"users", "user_roles", "user_bak", "orders", "log_2023", "log_x", "tmp_import", "schema_migrations"}
+	for _, c := range []struct {
+		include, exclude string
+		want             string
+	}{
+		{"", "", strings.Join(names, ",")},
+		{"*", "*_bak,tmp_*,schema_migrations", "users,user_roles,orders,log_2023,log_x"},
+		{"users", "", "users"},
+		{"users, orders", "", "users,orders"},
+		{"user_*", "*_bak", "user_roles"},
+		{`/^log_\d{1,4}$/,orders`, "", "orders,log_2023"},
+		{"user?", "", "users"},
+		{"", `/_(bak|import)$/`, "users,user_roles,orders,log_2023,log_x,schema_migrations"},
+	} {
+		var exclude []string
+		if c.exclude != "" {
+			exclude = []string{c.exclude}
+		}
+		f, err := NewTableFilter([]string{c.include}, exclude)
+		if err != nil {
+			t.Fatalf("%q %q: %s", c.include, c.exclude, err)
+		}
+		if got := strings.Join(f.Filter(names), ","); got != c.want {
+			t.Errorf("include %q exclude %q = %s, want %s", c.include, c.exclude, got, c.want)
+		}
+	}
+
+	f, _ := NewTableFilter([]string{"users"}, nil)
+	if name, ok := f.Name(); !ok || name != "users" {
+		t.Errorf("Name() = %s, %v, want users", name, ok)
+	}
+	for _, include := range []string{"*", "user_*", "users,orders", "/users/"} {
+		f, _ = NewTableFilter([]string{include}, nil)
+		if _, ok := f.Name(); ok {
+			t.Errorf("%s should not be a single table", include)
+		}
+	}
+
+	// pflag splits --exclude on commas
+	f, _ = NewTableFilter(nil, []string{`/^log_\d{1`, `4}$/`, "users"})
+	if got := strings.Join(f.Filter(names), ","); got != "user_roles,user_bak,orders,log_x,tmp_import,schema_migrations" {
+		t.Errorf("split regexp exclude = %s", got)
+	}
+
+	if _, err := NewTableFilter([]string{"/(/"}, nil); err == nil {
+		t.Error("invalid regexp should fail")
+	}
+	if _, err := NewTableFilter([]string{"[a"}, nil); err == nil {
+		t.Error("invalid glob should fail")
+	}
+}
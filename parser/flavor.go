@@ -0,0 +1,417 @@
+package parser
+
+import (
+	"go/format"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/mysql"
+	"github.com/pkg/errors"
+)
+
+// Flavor is the library the models are written for, it decides the struct tags and helpers.
+type Flavor string
+
+const (
+	// FlavorGorm writes gorm tags, column:id;primaryKey.
+	FlavorGorm Flavor = "gorm"
+
+	// FlavorSQLx writes db tags for sqlx and database/sql, with the Columns and ScanFields helpers.
+	FlavorSQLx Flavor = "sqlx"
+
+	// FlavorBun writes bun tags and embeds bun.BaseModel with the table name.
+	FlavorBun Flavor = "bun"
+
+	// FlavorXorm writes xorm tags, 'id' pk autoincr.
+	FlavorXorm Flavor = "xorm"
+
+	// FlavorEnt writes an ent schema per table instead of a struct, see entgo.io/ent/schema.
+	FlavorEnt Flavor = "ent"
+)
+
+var sqlFuncRe = regexp.MustCompile(`^[A-Za-z_]+\(.*\)$`)
+
+// sqlKeywords are the defaults written unquoted besides numbers and function calls,
+// any other word is a string such as the enum value 'ACTIVE'.
+var sqlKeywords = map[string]bool{
+	"CURRENT_TIMESTAMP": true,
+	"CURRENT_DATE":      true,
+	"CURRENT_TIME":      true,
+	"LOCALTIME":         true,
+	"LOCALTIMESTAMP":    true,
+}
+
+var (
+	entTmplRaw   string
+	entTmpl      *template.Template
+	entParseOnce sync.Once
+)
+
+// ParseFlavor checks the flavor name given by flag or config, empty name is gorm.
+func ParseFlavor(name string) (Flavor, error) {
+	switch f := Flavor(strings.ToLower(name)); f {
+	case "":
+		return FlavorGorm, nil
+	case FlavorGorm, FlavorSQLx, FlavorBun, FlavorXorm, FlavorEnt:
+		return f, nil
+	}
+	return "", errors.Errorf("unsupported flavor: %s, use gorm, sqlx, bun, xorm or ent", name)
+}
+
+// flavorTag returns the struct tag key and value of col for the flavor of opt.
+func flavorTag(table *Table, col *Column, opt options) []string {
+	switch opt.Flavor {
+	case FlavorSQLx:
+		return []string{"db", col.Name}
+	case FlavorBun:
+		return []string{"bun", bunTag(table, col, opt)}
+	case FlavorXorm:
+		return []string{"xorm", xormTag(table, col, opt)}
+	default:
+		return []string{"gorm", gormTag(table, col, opt)}
+	}
+}
+
+// bunTag is the bun tag of col, e.g. id,pk,autoincrement.
+func bunTag(table *Table, col *Column, opt options) string {
+	parts := []string{col.Name}
+	if col.PrimaryKey {
+		parts = append(parts, "pk")
+	}
+	if col.AutoIncrement {
+		parts = append(parts, "autoincrement")
+	}
+	if opt.GormType {
+		parts = append(parts, "type:"+col.SQLType)
+	}
+	if !col.PrimaryKey && col.NotNull {
+		parts = append(parts, "notnull")
+	}
+	if col.Unique {
+		parts = append(parts, "unique")
+	}
+	for _, index := range table.Indexes {
+		if index.Unique && len(index.Columns) > 1 && containsColumn(index.Columns, col.Name) {
+			parts = append(parts, "unique:"+index.Name)
+		} else if index.Unique && containsColumn(index.Columns, col.Name) {
+			parts = append(parts, "unique")
+		}
+	}
+	if col.Default != "" && !strings.ContainsAny(col.Default, `,"`) {
+		parts = append(parts, "default:"+sqlDefault(col.Default))
+	}
+	if table.View {
+		// read only, bun only scans the field
+		parts = append(parts, "scanonly")
+	}
+	return strings.Join(parts, ",")
+}
+
+// xormTag is the xorm tag of col, e.g. 'id' pk autoincr.
+func xormTag(table *Table, col *Column, opt options) string {
+	parts := []string{"'" + col.Name + "'"}
+	if opt.GormType {
+		parts = append(parts, col.SQLType)
+	}
+	if col.PrimaryKey {
+		parts = append(parts, "pk")
+	}
+	if col.AutoIncrement {
+		parts = append(parts, "autoincr")
+	}
+	if !col.PrimaryKey && col.NotNull {
+		parts = append(parts, "notnull")
+	}
+	if col.Unique {
+		parts = append(parts, "unique")
+	}
+	for _, index := range table.Indexes {
+		switch {
+		case !containsColumn(index.Columns, col.Name):
+		case index.Unique:
+			parts = append(parts, "unique("+index.Name+")")
+		default:
+			parts = append(parts, "index("+index.Name+")")
+		}
+	}
+	if table.View {
+		// read only, xorm never writes the field
+		parts = append(parts, "<-")
+	}
+	return strings.Join(parts, " ")
+}
+
+// containsColumn .
+func containsColumn(columns []string, name string) bool {
+	for _, col := range columns {
+		if strings.EqualFold(col, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlDefault quotes a string default, numbers and functions such as CURRENT_TIMESTAMP are kept.
+func sqlDefault(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil || isSQLFunc(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// isSQLFunc reports whether a default is a function call or one of sqlKeywords.
+func isSQLFunc(value string) bool {
+	return sqlKeywords[strings.ToUpper(value)] || sqlFuncRe.MatchString(value)
+}
+
+// entData is the data of the ent schema template, one per table.
+type entData struct {
+	Name    string   `json:"-"` // schema name, the go struct name
+	Comment string   `json:"-"`
+	Table   string   `json:"-"`
+	View    bool     `json:"-"`
+	Fields  []string `json:"-"` // field builders, e.g. field.Int64("id")
+	Indexes []string `json:"-"` // index builders, e.g. index.Fields("a", "b").Unique()
+}
+
+// entDialects are the ent dialect names of the drivers, the keys of SchemaType.
+var entDialects = map[Driver]string{
+	DriverMySQL:    "dialect.MySQL",
+	DriverPostgres: "dialect.Postgres",
+	DriverSQLite:   "dialect.SQLite",
+}
+
+// entFieldTypes are the ent field builders of the go field types.
+var entFieldTypes = map[string]string{
+	"int":       "Int",
+	"int32":     "Int32",
+	"int64":     "Int64",
+	"uint":      "Uint",
+	"uint32":    "Uint32",
+	"uint64":    "Uint64",
+	"float32":   "Float32",
+	"float64":   "Float",
+	"bool":      "Bool",
+	"string":    "String",
+	"time.Time": "Time",
+	"[]byte":    "Bytes",
+}
+
+// makeEntCode renders the ent schema of table and the import paths it needs,
+// the fields are built from the same column walk as the struct.
+func makeEntCode(table *Table, opt options) (string, []string, error) {
+	entParseOnce.Do(func() {
+		entTmpl = template.Must(template.New("goEnt").Parse(entTmplRaw))
+	})
+
+	opt.NullStyle = NullDisable
+	opt.TypeMapping = nil
+	opt.Associations = false
+	model, _ := makeTmplData(table, opt)
+
+	data := entData{Name: model.TableName, Comment: model.Comment, Table: table.Name, View: table.View}
+	enums := make(map[string]TmplEnum, len(model.Enums))
+	for _, enum := range model.Enums {
+		enums[enum.Column] = enum
+	}
+	var pks int
+	for _, col := range table.Columns {
+		if col.PrimaryKey {
+			pks++
+		}
+	}
+	dialect := entDialects[table.Driver]
+	if dialect == "" {
+		dialect = entDialects[DriverMySQL]
+	}
+
+	imports := map[string]bool{
+		"entgo.io/ent":                true,
+		"entgo.io/ent/dialect/entsql": true,
+		"entgo.io/ent/schema":         true,
+		"entgo.io/ent/schema/field":   true,
+	}
+	for i, f := range model.Fields {
+		col := table.Columns[i]
+		name := strconv.Quote(f.ColumnName)
+		// ent names the primary key id, the column keeps its name
+		storageKey := ""
+		if col.PrimaryKey && pks == 1 && f.ColumnName != "id" {
+			name, storageKey = `"id"`, f.ColumnName
+		}
+
+		var b strings.Builder
+		schemaType := false
+		enum, isEnum := enums[f.ColumnName]
+		builder, ok := entFieldTypes[f.GoType]
+		switch {
+		case isEnum && !enum.Set:
+			values := make([]string, 0, len(enum.Values))
+			for _, v := range enum.Values {
+				values = append(values, strconv.Quote(v.Value))
+			}
+			b.WriteString("field.Enum(" + name + ").Values(" + strings.Join(values, ", ") + ")")
+		case ok && !isEnum:
+			if builder == "String" && col.Tp != nil && isBlobType(col.Tp.Tp) {
+				builder = "Text"
+			}
+			b.WriteString("field." + builder + "(" + name + ")")
+			if builder == "String" && col.Tp != nil && col.Tp.Flen > 0 &&
+				(col.Tp.Tp == mysql.TypeVarchar || col.Tp.Tp == mysql.TypeVarString || col.Tp.Tp == mysql.TypeString) {
+				b.WriteString(".MaxLen(" + strconv.Itoa(col.Tp.Flen) + ")")
+			}
+		case f.ImportPath != "" && !isEnum:
+			// decimal.Decimal, pq arrays: a ValueScanner with the column type
+			b.WriteString("field.Other(" + name + ", " + f.GoType + "{})")
+			imports[f.ImportPath] = true
+			schemaType = true
+		default:
+			// set columns and the types without an ent field are strings of the column type
+			b.WriteString("field.String(" + name + ")")
+			schemaType = true
+		}
+		if schemaType {
+			b.WriteString(".SchemaType(map[string]string{" + dialect + ": " + strconv.Quote(col.SQLType) + "})")
+			imports["entgo.io/ent/dialect"] = true
+		}
+		if storageKey != "" {
+			b.WriteString(".StorageKey(" + strconv.Quote(storageKey) + ")")
+		}
+		if col.Default != "" {
+			def, pkg := entDefault(col.Default, builder, isEnum && !enum.Set)
+			b.WriteString(def)
+			if pkg != "" {
+				imports[pkg] = true
+			}
+		}
+		if f.Nullable {
+			b.WriteString(".Optional().Nillable()")
+		}
+		if col.Unique {
+			b.WriteString(".Unique()")
+		}
+		if table.View || col.AutoIncrement {
+			b.WriteString(".Immutable()")
+		}
+		if comment := strings.Join(strings.Fields(f.Comment), " "); comment != "" {
+			b.WriteString(".Comment(" + strconv.Quote(comment) + ")")
+		}
+		data.Fields = append(data.Fields, b.String())
+	}
+
+	for _, index := range table.Indexes {
+		if index.Fulltext {
+			continue
+		}
+		fields := make([]string, 0, len(index.Columns))
+		for _, col := range index.Columns {
+			fields = append(fields, strconv.Quote(col))
+		}
+		expr := "index.Fields(" + strings.Join(fields, ", ") + ")"
+		if index.Unique {
+			expr += ".Unique()"
+		}
+		data.Indexes = append(data.Indexes, expr+".StorageKey("+strconv.Quote(index.Name)+")")
+		imports["entgo.io/ent/schema/index"] = true
+	}
+
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	builder := strings.Builder{}
+	if err := entTmpl.Execute(&builder, data); err != nil {
+		return "", nil, err
+	}
+	code, err := format.Source([]byte(builder.String()))
+	if err != nil {
+		return builder.String(), paths, errors.WithMessage(err, "format golang code error")
+	}
+	return string(code), paths, nil
+}
+
+// entDefault is the default of a field built by builder, .Default of the go value when the builder takes it,
+// otherwise the column default is kept by an entsql annotation.
+func entDefault(value, builder string, enum bool) (string, string) {
+	isFunc := isSQLFunc(value)
+	switch {
+	case enum:
+		return ".Default(" + strconv.Quote(value) + ")", ""
+	case builder == "Time" && isFunc && strings.HasPrefix(strings.ToUpper(value), "CURRENT_TIMESTAMP"):
+		return ".Default(time.Now)", "time"
+	case builder == "String" || builder == "Text":
+		if !isFunc {
+			return ".Default(" + strconv.Quote(value) + ")", ""
+		}
+	case builder == "Bool":
+		if v, err := strconv.ParseBool(value); err == nil {
+			return ".Default(" + strconv.FormatBool(v) + ")", ""
+		}
+	case strings.HasPrefix(builder, "Int") || strings.HasPrefix(builder, "Uint"):
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return ".Default(" + value + ")", ""
+		}
+	case builder == "Float":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return ".Default(" + value + ")", ""
+		}
+	}
+	if isFunc {
+		return ".Annotations(entsql.DefaultExpr(" + strconv.Quote(value) + "))", ""
+	}
+	return ".Annotations(entsql.Default(" + strconv.Quote(value) + "))", ""
+}
+
+// isBlobType .
+func isBlobType(tp byte) bool {
+	switch tp {
+	case mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
+		return true
+	}
+	return false
+}
+
+func init() {
+	entTmplRaw = `
+{{- if .Comment -}}
+// {{.Comment}}
+{{end -}}
+{{- if .View -}}
+// {{.Name}} is read-only, it maps the view {{.Table}}.
+{{end -}}
+type {{.Name}} struct {
+	ent.Schema
+}
+
+// Annotations of {{.Name}}.
+func ({{.Name}}) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.Annotation{Table: "{{.Table}}"},
+	}
+}
+
+// Fields of {{.Name}}.
+func ({{.Name}}) Fields() []ent.Field {
+	return []ent.Field{
+{{- range .Fields}}
+		{{.}},
+{{- end}}
+	}
+}
+{{if .Indexes}}
+// Indexes of {{.Name}}.
+func ({{.Name}}) Indexes() []ent.Index {
+	return []ent.Index{
+{{- range .Indexes}}
+		{{.}},
+{{- end}}
+	}
+}
+{{end}}`
+}
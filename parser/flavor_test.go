@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const flavorUsersSQL = "CREATE TABLE `users` (\n" +
+	"  `user_id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `email` varchar(128) NOT NULL,\n" +
+	"  `status` enum('active','banned') NOT NULL DEFAULT 'active',\n" +
+	"  `nickname` varchar(64) NULL COMMENT 'display name',\n" +
+	"  `balance` decimal(10,2) NOT NULL DEFAULT '0.00',\n" +
+	"  `bio` text NULL,\n" +
+	"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+	"  PRIMARY KEY (`user_id`),\n" +
+	"  UNIQUE KEY `uk_email` (`email`),\n" +
+	"  KEY `idx_status_created` (`status`,`created_at`)\n" +
+	") COMMENT='用户';"
+
+func TestFlavors(t *testing.T) {
+	cases := []struct {
+		flavor Flavor
+		want   []string
+		imp    string
+	}{
+		{FlavorGorm, []string{`gorm:"column:email`}, ""},
+		{FlavorSQLx, []string{
+			"`json:\"email\" db:\"email\"`",
+			`return []string{"user_id", "email", "status", "nickname", "balance", "bio", "created_at"}`,
+			`return []interface{}{&m.UserID, &m.Email, &m.Status, &m.Nickname, &m.Balance, &m.Bio, &m.CreatedAt}`,
+		}, ""},
+		{FlavorBun, []string{
+			"bun.BaseModel `bun:\"table:users\"`",
+			`bun:"user_id,pk,autoincrement"`,
+			`bun:"email,notnull,unique"`,
+			`bun:"status,notnull,default:'active'"`,
+		}, "github.com/uptrace/bun"},
+		{FlavorXorm, []string{
+			`xorm:"'user_id' pk autoincr"`,
+			`xorm:"'email' notnull unique(uk_email)"`,
+			`xorm:"'created_at' notnull index(idx_status_created)"`,
+		}, ""},
+		{FlavorEnt, []string{
+			"type Users struct {\n\tent.Schema\n}",
+			`entsql.Annotation{Table: "users"}`,
+			`field.Int64("id").StorageKey("user_id").Immutable(),`,
+			`field.Enum("status").Values("active", "banned").Default("active"),`,
+			`field.String("nickname").MaxLen(64).Optional().Nillable().Comment("display name"),`,
+			`field.Other("balance", decimal.Decimal{}).SchemaType(map[string]string{dialect.MySQL: "decimal(10,2)"}).` +
+				`Annotations(entsql.Default("0.00")),`,
+			`field.Time("created_at").Default(time.Now),`,
+			`field.Text("bio").Optional().Nillable(),`,
+			`index.Fields("status", "created_at").StorageKey("idx_status_created"),`,
+		}, "entgo.io/ent/schema/field"},
+	}
+	for _, c := range cases {
+		data, err := ParseSQL(flavorUsersSQL, WithFlavor(c.flavor), WithJSONTag(), WithForceTableName())
+		if err != nil {
+			t.Fatal(err)
+		}
+		code := data.StructCode[0]
+		for _, want := range c.want {
+			if !strings.Contains(code, want) {
+				t.Errorf("%s: want %s in\n%s", c.flavor, want, code)
+			}
+		}
+		if c.flavor != FlavorGorm && strings.Contains(code, "gorm:") {
+			t.Errorf("%s: unexpected gorm tag in\n%s", c.flavor, code)
+		}
+		// only gorm and xorm read the TableName method
+		if hasName := strings.Contains(code, "TableName() string"); hasName != (c.flavor == FlavorGorm || c.flavor == FlavorXorm) {
+			t.Errorf("%s: TableName() written %v in\n%s", c.flavor, hasName, code)
+		}
+		if c.flavor != FlavorSQLx && strings.Contains(code, "ScanFields") {
+			t.Errorf("%s: unexpected sqlx helpers in\n%s", c.flavor, code)
+		}
+		if c.imp != "" && !containsColumn(data.ImportPath, c.imp) {
+			t.Errorf("%s: want import %s in %v", c.flavor, c.imp, data.ImportPath)
+		}
+	}
+}
+
+func TestFlavorDefaults(t *testing.T) {
+	const ddl = "CREATE TABLE `accounts` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `state` enum('ACTIVE','INACTIVE') NOT NULL DEFAULT 'ACTIVE',\n" +
+		"  `code` varchar(8) NOT NULL DEFAULT 'NONE',\n" +
+		"  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");"
+	// all caps strings are quoted, only the functions such as CURRENT_TIMESTAMP are not
+	for flavor, want := range map[Flavor][]string{
+		FlavorBun: {
+			`bun:"state,notnull,default:'ACTIVE'"`,
+			`bun:"code,notnull,default:'NONE'"`,
+			`bun:"created_at,notnull,default:CURRENT_TIMESTAMP"`,
+		},
+		FlavorEnt: {
+			`field.Enum("state").Values("ACTIVE", "INACTIVE").Default("ACTIVE"),`,
+			`field.String("code").MaxLen(8).Default("NONE"),`,
+			`field.Time("created_at").Default(time.Now),`,
+		},
+	} {
+		data, err := ParseSQL(ddl, WithFlavor(flavor))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range want {
+			if !strings.Contains(data.StructCode[0], s) {
+				t.Errorf("%s: want %s in\n%s", flavor, s, data.StructCode[0])
+			}
+		}
+	}
+}
+
+func TestParseFlavor(t *testing.T) {
+	if f, err := ParseFlavor(""); err != nil || f != FlavorGorm {
+		t.Errorf("empty flavor: %s, %v", f, err)
+	}
+	if f, err := ParseFlavor("SQLx"); err != nil || f != FlavorSQLx {
+		t.Errorf("SQLx: %s, %v", f, err)
+	}
+	if _, err := ParseFlavor("sqlc"); err == nil {
+		t.Error("want error of unsupported flavor")
+	}
+}
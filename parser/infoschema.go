@@ -0,0 +1,373 @@
+package parser
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const mysqlInfoTablesSQL = `SELECT TABLE_NAME, TABLE_COMMENT, TABLE_TYPE = 'VIEW' FROM information_schema.TABLES
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE IN ('BASE TABLE', 'VIEW')`
+
+const mysqlInfoColumnsSQL = `SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE = 'YES', COLUMN_DEFAULT, EXTRA, COLUMN_COMMENT
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = DATABASE()
+ORDER BY TABLE_NAME, ORDINAL_POSITION`
+
+const mysqlInfoViewColumnsSQL = `SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE = 'YES', COLUMN_DEFAULT, EXTRA, COLUMN_COMMENT
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+ORDER BY ORDINAL_POSITION`
+
+const mysqlInfoIndexesSQL = `SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, INDEX_TYPE, COLUMN_NAME, SUB_PART
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = DATABASE()
+ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`
+
+const mysqlInfoForeignKeysSQL = `SELECT TABLE_NAME, CONSTRAINT_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+FROM information_schema.KEY_COLUMN_USAGE
+WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION`
+
+// infoTable is a mysql table read from information_schema.
+type infoTable struct {
+	Name        string
+	Comment     string
+	View        bool
+	Columns     []infoColumn
+	Indexes     []*infoIndex
+	ForeignKeys []*ForeignKey
+}
+
+// infoColumn .
+type infoColumn struct {
+	Name       string
+	ColumnType string // e.g. int(10) unsigned, enum('a','b')
+	Nullable   bool
+	Default    sql.NullString
+	Extra      string // auto_increment, on update CURRENT_TIMESTAMP, DEFAULT_GENERATED
+	Comment    string
+}
+
+// infoIndex .
+type infoIndex struct {
+	Name     string
+	Unique   bool
+	Fulltext bool
+	// Columns are quoted with the prefix length, e.g. `name`(10)
+	Columns []string
+	// expression indexes (mysql 8) have no column name and are skipped
+	skip bool
+}
+
+// BulkTables reads the tables through a handful of information_schema queries instead of
+// one SHOW CREATE TABLE per table, names nil means all tables, views included if IncludeViews is set.
+// Only mysql is supported in bulk, the tables of other drivers are read by ReadTables.
+// The queries are canceled once ctx is done.
+func (in *Introspector) BulkTables(ctx context.Context, names []string, options ...Option) ([]*Table, error) {
+	if in.driver != DriverMySQL {
+		if names == nil {
+			var err error
+			if names, err = in.Tables("*"); err != nil {
+				return nil, err
+			}
+		}
+		return in.ReadTables(ctx, names, options...)
+	}
+
+	infos, err := readInfoSchema(ctx, in.db)
+	if err != nil {
+		return nil, err
+	}
+	if names == nil {
+		names = make([]string, 0, len(infos))
+		for name, info := range infos {
+			if !info.View || in.views {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	tables := make([]*Table, 0, len(names))
+	for _, name := range names {
+		info, ok := infos[name]
+		if !ok {
+			return nil, errors.Errorf("table(%s) not found", name)
+		}
+		// parsed as SHOW CREATE TABLE output, so both modes build the same table
+		table, err := parseCreateTable(info.createTableSQL(), name, options...)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "parse table %s error", name)
+		}
+		table.View = info.View
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// readInfoSchema reads all tables of the current database.
+func readInfoSchema(ctx context.Context, db *sql.DB) (map[string]*infoTable, error) {
+	tables := make(map[string]*infoTable)
+
+	rows, err := db.QueryContext(ctx, mysqlInfoTablesSQL)
+	if err != nil {
+		return nil, errors.WithMessage(err, "query information_schema.TABLES error")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		table := &infoTable{}
+		if err = rows.Scan(&table.Name, &table.Comment, &table.View); err != nil {
+			return nil, err
+		}
+		if table.View {
+			// TABLE_COMMENT of a view is "VIEW"
+			table.Comment = ""
+		}
+		tables[table.Name] = table
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = readInfoColumns(ctx, db, tables); err != nil {
+		return nil, err
+	}
+	if err = readInfoIndexes(ctx, db, tables); err != nil {
+		return nil, err
+	}
+	if err = readInfoForeignKeys(ctx, db, tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// readInfoColumns .
+func readInfoColumns(ctx context.Context, db *sql.DB, tables map[string]*infoTable) error {
+	rows, err := db.QueryContext(ctx, mysqlInfoColumnsSQL)
+	if err != nil {
+		return errors.WithMessage(err, "query information_schema.COLUMNS error")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName string
+		var col infoColumn
+		if err = rows.Scan(&tableName, &col.Name, &col.ColumnType, &col.Nullable, &col.Default, &col.Extra, &col.Comment); err != nil {
+			return err
+		}
+		if table, ok := tables[tableName]; ok {
+			table.Columns = append(table.Columns, col)
+		}
+	}
+	return rows.Err()
+}
+
+// mysqlView reads the columns of a view from information_schema.COLUMNS,
+// a view has no keys so the table only holds columns.
+func mysqlView(ctx context.Context, db *sql.DB, viewName string, options ...Option) (*Table, error) {
+	rows, err := db.QueryContext(ctx, mysqlInfoViewColumnsSQL, viewName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "query information_schema.COLUMNS error")
+	}
+	defer rows.Close()
+	info := &infoTable{Name: viewName, View: true}
+	for rows.Next() {
+		var col infoColumn
+		if err = rows.Scan(&col.Name, &col.ColumnType, &col.Nullable, &col.Default, &col.Extra, &col.Comment); err != nil {
+			return nil, err
+		}
+		info.Columns = append(info.Columns, col)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(info.Columns) == 0 {
+		return nil, errors.Errorf("view(%s) not found", viewName)
+	}
+
+	table, err := parseCreateTable(info.createTableSQL(), viewName, options...)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "parse view %s error", viewName)
+	}
+	table.View = true
+	return table, nil
+}
+
+// readInfoIndexes .
+func readInfoIndexes(ctx context.Context, db *sql.DB, tables map[string]*infoTable) error {
+	rows, err := db.QueryContext(ctx, mysqlInfoIndexesSQL)
+	if err != nil {
+		return errors.WithMessage(err, "query information_schema.STATISTICS error")
+	}
+	defer rows.Close()
+	var index *infoIndex
+	var lastTable string
+	for rows.Next() {
+		var (
+			tableName, indexName, indexType string
+			nonUnique                       bool
+			colName                         sql.NullString
+			subPart                         sql.NullInt64
+		)
+		if err = rows.Scan(&tableName, &indexName, &nonUnique, &indexType, &colName, &subPart); err != nil {
+			return err
+		}
+		table, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+		if index == nil || index.Name != indexName || lastTable != tableName {
+			index = &infoIndex{Name: indexName, Unique: !nonUnique, Fulltext: indexType == "FULLTEXT"}
+			table.Indexes = append(table.Indexes, index)
+			lastTable = tableName
+		}
+		if !colName.Valid {
+			index.skip = true
+			continue
+		}
+		col := quoteName(colName.String)
+		if subPart.Valid {
+			col += fmt.Sprintf("(%d)", subPart.Int64)
+		}
+		index.Columns = append(index.Columns, col)
+	}
+	return rows.Err()
+}
+
+// readInfoForeignKeys .
+func readInfoForeignKeys(ctx context.Context, db *sql.DB, tables map[string]*infoTable) error {
+	rows, err := db.QueryContext(ctx, mysqlInfoForeignKeysSQL)
+	if err != nil {
+		return errors.WithMessage(err, "query information_schema.KEY_COLUMN_USAGE error")
+	}
+	defer rows.Close()
+	var fk *ForeignKey
+	var lastTable string
+	for rows.Next() {
+		var tableName, fkName, colName, refTable, refColumn string
+		if err = rows.Scan(&tableName, &fkName, &colName, &refTable, &refColumn); err != nil {
+			return err
+		}
+		table, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+		if fk == nil || fk.Name != fkName || lastTable != tableName {
+			fk = &ForeignKey{Name: fkName, RefTable: refTable}
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+			lastTable = tableName
+		}
+		fk.Columns = append(fk.Columns, colName)
+		fk.RefColumns = append(fk.RefColumns, refColumn)
+	}
+	return rows.Err()
+}
+
+// createTableSQL rebuilds the statement the way SHOW CREATE TABLE prints it: primary key,
+// unique keys, keys and fulltext keys, each group ordered by name, then the foreign keys.
+func (t *infoTable) createTableSQL() string {
+	lines := make([]string, 0, len(t.Columns)+len(t.Indexes)+len(t.ForeignKeys))
+	for _, col := range t.Columns {
+		lines = append(lines, col.definition())
+	}
+
+	indexes := make([]*infoIndex, 0, len(t.Indexes))
+	for _, index := range t.Indexes {
+		if !index.skip {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if a, b := indexes[i].order(), indexes[j].order(); a != b {
+			return a < b
+		}
+		return indexes[i].Name < indexes[j].Name
+	})
+	for _, index := range indexes {
+		lines = append(lines, index.definition())
+	}
+
+	for _, fk := range t.ForeignKeys {
+		lines = append(lines, fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			quoteName(fk.Name), quoteNames(fk.Columns), quoteName(fk.RefTable), quoteNames(fk.RefColumns)))
+	}
+
+	sql := "CREATE TABLE " + quoteName(t.Name) + " (\n  " + strings.Join(lines, ",\n  ") + "\n)"
+	if t.Comment != "" {
+		sql += " COMMENT=" + quoteString(t.Comment)
+	}
+	return sql
+}
+
+// definition .
+func (c infoColumn) definition() string {
+	def := quoteName(c.Name) + " " + c.ColumnType
+	if !c.Nullable {
+		def += " NOT NULL"
+	} else if strings.HasPrefix(strings.ToLower(c.ColumnType), "timestamp") {
+		// SHOW CREATE TABLE writes NULL explicitly only for timestamp columns
+		def += " NULL"
+	}
+	if c.Default.Valid {
+		def += " DEFAULT " + c.defaultSQL()
+	} else if c.Nullable {
+		def += " DEFAULT NULL"
+	}
+	extra := strings.ToLower(c.Extra)
+	if strings.Contains(extra, "auto_increment") {
+		def += " AUTO_INCREMENT"
+	}
+	if strings.Contains(extra, "on update current_timestamp") {
+		def += " ON UPDATE CURRENT_TIMESTAMP"
+	}
+	if c.Comment != "" {
+		def += " COMMENT " + quoteString(c.Comment)
+	}
+	return def
+}
+
+// defaultSQL quotes literal defaults, CURRENT_TIMESTAMP and bit values are kept as they are,
+// mysql 8 expression defaults are written in parentheses.
+func (c infoColumn) defaultSQL() string {
+	value := c.Default.String
+	switch upper := strings.ToUpper(value); {
+	case strings.HasPrefix(upper, "CURRENT_TIMESTAMP"), strings.HasPrefix(upper, "B'"):
+		return value
+	case strings.Contains(strings.ToLower(c.Extra), "default_generated"):
+		return "(" + value + ")"
+	}
+	return quoteString(value)
+}
+
+// order .
+func (i *infoIndex) order() int {
+	switch {
+	case i.Name == "PRIMARY":
+		return 0
+	case i.Unique:
+		return 1
+	case i.Fulltext:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// definition .
+func (i *infoIndex) definition() string {
+	cols := strings.Join(i.Columns, ", ")
+	switch {
+	case i.Name == "PRIMARY":
+		return "PRIMARY KEY (" + cols + ")"
+	case i.Unique:
+		return "UNIQUE KEY " + quoteName(i.Name) + " (" + cols + ")"
+	case i.Fulltext:
+		return "FULLTEXT KEY " + quoteName(i.Name) + " (" + cols + ")"
+	default:
+		return "KEY " + quoteName(i.Name) + " (" + cols + ")"
+	}
+}
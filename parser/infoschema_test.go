@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestInfoTableCreateTableSQL(t *testing.T) {
+	info := &infoTable{
+		Name:    "orders",
+		Comment: "user's orders",
+		Columns: []infoColumn{
+			{Name: "id", ColumnType: "bigint(20) unsigned", Extra: "auto_increment"},
+			{Name: "user_id", ColumnType: "bigint(20) unsigned"},
+			{Name: "sn", ColumnType: "varchar(64)", Comment: "order number"},
+			{Name: "status", ColumnType: "enum('new','paid')", Default: sql.NullString{String: "new", Valid: true}},
+			{Name: "amount", ColumnType: "decimal(10,2)", Default: sql.NullString{String: "0.00", Valid: true}},
+			{Name: "note", ColumnType: "text", Nullable: true},
+			{Name: "created_at", ColumnType: "datetime",
+				Default: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, Extra: "DEFAULT_GENERATED"},
+			{Name: "updated_at", ColumnType: "timestamp", Nullable: true,
+				Default: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, Extra: "on update CURRENT_TIMESTAMP"},
+		},
+		Indexes: []*infoIndex{
+			{Name: "idx_user_status", Columns: []string{"`user_id`", "`status`"}},
+			{Name: "PRIMARY", Unique: true, Columns: []string{"`id`"}},
+			{Name: "ft_note", Fulltext: true, Columns: []string{"`note`"}},
+			{Name: "idx_expr", skip: true},
+			{Name: "uk_sn", Unique: true, Columns: []string{"`sn`"}},
+		},
+		ForeignKeys: []*ForeignKey{
+			{Name: "fk_orders_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}},
+		},
+	}
+
+	showCreate := "CREATE TABLE `orders` (\n" +
+		"  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` bigint(20) unsigned NOT NULL,\n" +
+		"  `sn` varchar(64) NOT NULL COMMENT 'order number',\n" +
+		"  `status` enum('new','paid') NOT NULL DEFAULT 'new',\n" +
+		"  `amount` decimal(10,2) NOT NULL DEFAULT '0.00',\n" +
+		"  `note` text,\n" +
+		"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_sn` (`sn`),\n" +
+		"  KEY `idx_user_status` (`user_id`,`status`),\n" +
+		"  FULLTEXT KEY `ft_note` (`note`),\n" +
+		"  CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='user''s orders'"
+
+	bulk, err := parseCreateTable(info.createTableSQL(), "orders")
+	if err != nil {
+		t.Fatalf("%s\n%s", err, info.createTableSQL())
+	}
+	show, err := parseCreateTable(showCreate, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opt := []Option{WithJSONTag(), WithGormType(), WithAssociations()}
+	bulkCodes, err := ParseTables([]*Table{bulk}, opt...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	showCodes, err := ParseTables([]*Table{show}, opt...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bulkCodes.StructCode[0] != showCodes.StructCode[0] {
+		t.Errorf("information_schema:\n%s\nSHOW CREATE TABLE:\n%s", bulkCodes.StructCode[0], showCodes.StructCode[0])
+	}
+	if len(bulk.ForeignKeys) != 1 || bulk.ForeignKeys[0].RefTable != "users" {
+		t.Errorf("unexpected foreign keys: %v", bulk.ForeignKeys)
+	}
+}
@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultJobs is the number of tables read at the same time when jobs is not set.
+const DefaultJobs = 8
+
+// Introspector reads the tables of one database through a single connection pool,
+// at most jobs tables are read at the same time.
+type Introspector struct {
+	driver Driver
+	db     *sql.DB
+	jobs   int
+	views  bool
+}
+
+// NewIntrospector opens the connection pool of dsn, jobs <= 0 means DefaultJobs.
+// The pool never holds more than jobs connections.
+func NewIntrospector(driver Driver, dsn string, jobs int) (*Introspector, error) {
+	if jobs <= 0 {
+		jobs = DefaultJobs
+	}
+
+	var db *sql.DB
+	var err error
+	switch driver {
+	case DriverPostgres:
+		db, err = sql.Open("postgres", dsn)
+	case DriverSQLite:
+		db, err = sql.Open("sqlite3", sqliteDSN(dsn))
+	default:
+		db, err = sql.Open("mysql", dsn)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "open db error")
+	}
+	db.SetMaxOpenConns(jobs)
+	db.SetMaxIdleConns(jobs)
+
+	return &Introspector{driver: driver, db: db, jobs: jobs}, nil
+}
+
+// Driver .
+func (in *Introspector) Driver() Driver {
+	return in.driver
+}
+
+// IncludeViews sets whether the views are listed with the tables, they are skipped by default.
+// A view named alone is read either way.
+func (in *Introspector) IncludeViews(include bool) {
+	in.views = include
+}
+
+// Close closes the connection pool.
+func (in *Introspector) Close() error {
+	return in.db.Close()
+}
+
+// Tables returns the tables to generate, table is a name, "*" for all tables or
+// comma separated patterns, see TableFilter.
+func (in *Introspector) Tables(table string) ([]string, error) {
+	filter, err := NewTableFilter([]string{table}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return in.FilterTables(filter)
+}
+
+// FilterTables lists the tables of the database matched by filter,
+// a single table name is returned without listing.
+func (in *Introspector) FilterTables(filter *TableFilter) ([]string, error) {
+	if name, ok := filter.Name(); ok {
+		return []string{name}, nil
+	}
+
+	names, views, err := in.listTables(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if in.views && len(views) > 0 {
+		names = append(names, views...)
+		sort.Strings(names)
+	}
+	return filter.Filter(names), nil
+}
+
+// TableNames lists all tables and views of the database, the views whether they are included or not.
+func (in *Introspector) TableNames() ([]string, error) {
+	tables, views, err := in.listTables(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	names := append(tables, views...)
+	sort.Strings(names)
+	return names, nil
+}
+
+// listTables .
+func (in *Introspector) listTables(ctx context.Context) (tables []string, views []string, err error) {
+	switch in.driver {
+	case DriverPostgres:
+		if tables, err = postgresTables(ctx, in.db); err == nil {
+			views, err = postgresViews(ctx, in.db)
+		}
+	case DriverSQLite:
+		if tables, err = sqliteTables(ctx, in.db); err == nil {
+			views, err = sqliteViews(ctx, in.db)
+		}
+	default:
+		tables, views, err = showFullTables(ctx, in.db)
+	}
+	return tables, views, err
+}
+
+// CreateTable returns the SHOW CREATE TABLE statement of a mysql table.
+func (in *Introspector) CreateTable(tableName string) (string, error) {
+	if in.driver != DriverMySQL {
+		return "", errors.Errorf("show create table is not supported by %s", in.driver)
+	}
+	createSQL, view, err := showCreateTable(context.Background(), in.db, tableName)
+	if err != nil {
+		return "", err
+	}
+	if view {
+		return "", errors.Errorf("%s is a view, it has no CREATE TABLE statement", tableName)
+	}
+	return createSQL, nil
+}
+
+// Table reads the definition of tableName, options are used to parse the mysql DDL (charset, collation).
+// A view is read with its columns only and Table.View set.
+func (in *Introspector) Table(tableName string, options ...Option) (*Table, error) {
+	return in.table(context.Background(), tableName, options...)
+}
+
+// table reads tableName, the queries are canceled with ctx.
+func (in *Introspector) table(ctx context.Context, tableName string, options ...Option) (*Table, error) {
+	switch in.driver {
+	case DriverPostgres:
+		return postgresTable(ctx, in.db, tableName)
+	case DriverSQLite:
+		return sqliteTable(ctx, in.db, tableName)
+	default:
+		return mysqlTable(ctx, in.db, tableName, options...)
+	}
+}
+
+// ReadTables reads the tables concurrently, at most jobs at the same time,
+// the result is in the order of names. Once ctx is done the running queries are canceled,
+// no more table is read and ctx.Err() is returned.
+func (in *Introspector) ReadTables(ctx context.Context, names []string, options ...Option) ([]*Table, error) {
+	tables := make([]*Table, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, in.jobs)
+	wg := &sync.WaitGroup{}
+loop:
+	for i, name := range names {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break loop
+		}
+		wg.Add(1)
+		go func(i int, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			tables[i], errs[i] = in.table(ctx, name, options...)
+		}(i, name)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.WithMessagef(err, "read table %s error", names[i])
+		}
+	}
+	return tables, nil
+}
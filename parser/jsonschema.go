@@ -0,0 +1,305 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/mysql"
+	"gopkg.in/yaml.v3"
+)
+
+// jsonSchemaDraft is the dialect of the JSON Schema documents.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaArrayItems are the item types of the postgres arrays.
+var schemaArrayItems = map[string][2]string{
+	"pq.Int64Array":   {"integer", "int64"},
+	"pq.Float64Array": {"number", "double"},
+	"pq.BoolArray":    {"boolean", ""},
+	"pq.ByteaArray":   {"string", "byte"},
+	"pq.StringArray":  {"string", ""},
+}
+
+// schemaObject is a JSON object which keeps the order of its keys.
+type schemaObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// newSchemaObject .
+func newSchemaObject() *schemaObject {
+	return &schemaObject{values: make(map[string]interface{})}
+}
+
+// set adds key, or replaces its value keeping its position.
+func (o *schemaObject) set(key string, value interface{}) *schemaObject {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+	return o
+}
+
+// MarshalJSON .
+func (o *schemaObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MakeJSONSchema renders the JSON Schema (draft 2020-12) document of the table object,
+// the properties are named after the columns like the json tags of WithJSONTag.
+func MakeJSONSchema(table *Table, options ...Option) (string, error) {
+	opt := parseOption(options)
+	name, schema := makeTableSchema(table, opt, false)
+	doc := newSchemaObject().
+		set("$schema", jsonSchemaDraft).
+		set("$comment", "Code generated by gmodel.").
+		set("title", name)
+	for _, key := range schema.keys {
+		doc.set(key, schema.values[key])
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+// ParseJSONSchemaToWrite .
+func ParseJSONSchemaToWrite(table *Table, writer io.Writer, options ...Option) error {
+	code, err := MakeJSONSchema(table, options...)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, code)
+	return err
+}
+
+// MakeOpenAPI renders the OpenAPI 3.0 components.schemas YAML of the table object,
+// nullable columns are written with nullable: true.
+func MakeOpenAPI(table *Table, options ...Option) (string, error) {
+	opt := parseOption(options)
+	name, schema := makeTableSchema(table, opt, true)
+	doc := newSchemaObject().set("components", newSchemaObject().set("schemas", newSchemaObject().set(name, schema)))
+	node, err := yamlNode(doc)
+	if err != nil {
+		return "", err
+	}
+	node.HeadComment = "Code generated by gmodel."
+
+	buf := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(node); err != nil {
+		return "", err
+	}
+	if err = encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ParseOpenAPIToWrite .
+func ParseOpenAPIToWrite(table *Table, writer io.Writer, options ...Option) error {
+	code, err := MakeOpenAPI(table, options...)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, code)
+	return err
+}
+
+// makeTableSchema returns the struct name and the object schema of table: NOT NULL columns are required,
+// varchar(n) has maxLength n and enum columns list their elements.
+func makeTableSchema(table *Table, opt options, openAPI bool) (string, *schemaObject) {
+	// the column walk of the go struct, with plain types since nullable is written apart
+	opt.NullStyle = NullDisable
+	opt.TypeMapping = nil
+	opt.Associations = false
+	model, _ := makeTmplData(table, opt)
+
+	enums := make(map[string]TmplEnum, len(model.Enums))
+	for _, enum := range model.Enums {
+		enums[enum.Column] = enum
+	}
+	properties := newSchemaObject()
+	var required []string
+	for i, f := range model.Fields {
+		var enum *TmplEnum
+		if e, ok := enums[f.ColumnName]; ok {
+			enum = &e
+		}
+		prop := columnSchema(f, table.Columns[i], enum, openAPI)
+		if table.View || f.AutoIncrement {
+			prop.set("readOnly", true)
+		}
+		if comment := strings.Join(strings.Fields(f.Comment), " "); comment != "" {
+			prop.set("description", comment)
+		}
+		properties.set(f.ColumnName, prop)
+		if !f.Nullable {
+			required = append(required, f.ColumnName)
+		}
+	}
+
+	schema := newSchemaObject()
+	if comment := strings.Join(strings.Fields(table.Comment), " "); comment != "" {
+		schema.set("description", comment)
+	}
+	schema.set("type", "object").set("properties", properties)
+	if len(required) > 0 {
+		schema.set("required", required)
+	}
+	return model.TableName, schema
+}
+
+// columnSchema is the schema of a column value, a nullable column also takes null.
+func columnSchema(f TmplField, col *Column, enum *TmplEnum, openAPI bool) *schemaObject {
+	prop := newSchemaObject()
+	typ, format := "string", ""
+	switch f.GoType {
+	case "int", "int32":
+		typ, format = "integer", "int32"
+	case "int64", "uint", "uint32":
+		// an unsigned int overflows int32
+		typ, format = "integer", "int64"
+	case "uint64":
+		typ = "integer"
+	case "float32":
+		typ, format = "number", "float"
+	case "float64":
+		typ, format = "number", "double"
+	case "bool":
+		typ = "boolean"
+	case "decimal.Decimal":
+		format = "decimal"
+	case "time.Time":
+		format = "date-time"
+		if col.Tp != nil && (col.Tp.Tp == mysql.TypeDate || col.Tp.Tp == mysql.TypeNewDate) {
+			format = "date"
+		}
+	case "[]byte":
+		format = "byte"
+	}
+	if strings.EqualFold(col.DBType, "uuid") {
+		format = "uuid"
+	}
+
+	var values []interface{}
+	if enum != nil {
+		for _, v := range enum.Values {
+			values = append(values, v.Value)
+		}
+	}
+	item, isArray := schemaArrayItems[f.GoType]
+	switch {
+	case enum != nil && enum.Set:
+		prop.set("type", schemaType("array", f.Nullable, openAPI))
+		prop.set("items", newSchemaObject().set("type", "string").set("enum", values))
+		prop.set("uniqueItems", true)
+	case isArray:
+		items := newSchemaObject().set("type", item[0])
+		if format := schemaFormat(item[1], openAPI); format != "" {
+			items.set("format", format)
+		}
+		prop.set("type", schemaType("array", f.Nullable, openAPI))
+		prop.set("items", items)
+	default:
+		prop.set("type", schemaType(typ, f.Nullable, openAPI))
+		if format = schemaFormat(format, openAPI); format != "" {
+			prop.set("format", format)
+		}
+		if f.GoType == "[]byte" && !openAPI {
+			prop.set("contentEncoding", "base64")
+		}
+	}
+	if f.Nullable && openAPI {
+		prop.set("nullable", true)
+	}
+
+	if col.Tp != nil && typ == "string" && enum == nil && col.Tp.Flen > 0 {
+		switch col.Tp.Tp {
+		case mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString:
+			prop.set("maxLength", col.Tp.Flen)
+		}
+	}
+	if col.Tp != nil && typ == "integer" && mysql.HasUnsignedFlag(col.Tp.Flag) {
+		prop.set("minimum", 0)
+	}
+	if enum != nil && !enum.Set {
+		if f.Nullable {
+			values = append(values, nil)
+		}
+		prop.set("enum", values)
+	}
+	return prop
+}
+
+// schemaType .
+func schemaType(typ string, nullable, openAPI bool) interface{} {
+	if nullable && !openAPI {
+		return []string{typ, "null"}
+	}
+	return typ
+}
+
+// schemaFormat drops the byte format of OpenAPI from JSON Schema, which has contentEncoding instead.
+func schemaFormat(format string, openAPI bool) string {
+	if format == "byte" && !openAPI {
+		return ""
+	}
+	return format
+}
+
+// yamlNode builds the yaml node of value, a schema object is a mapping in the order of its keys
+// and the scalars are encoded by yaml, which quotes them when needed.
+func yamlNode(value interface{}) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case *schemaObject:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range v.keys {
+			k, err := yamlNode(key)
+			if err != nil {
+				return nil, err
+			}
+			child, err := yamlNode(v.values[key])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, k, child)
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			child, err := yamlNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const schemaOrdersSQL = "CREATE TABLE `orders` (\n" +
+	"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `status` enum('new','paid') NULL COMMENT 'order status',\n" +
+	"  `tags` set('gift','urgent') NOT NULL,\n" +
+	"  `note` varchar(255) NULL,\n" +
+	"  `amount` decimal(10,2) NOT NULL,\n" +
+	"  `birthday` date NOT NULL,\n" +
+	"  `paid_at` datetime NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") COMMENT='订单';"
+
+func TestMakeJSONSchema(t *testing.T) {
+	tables, err := GetTablesFromSQL(schemaOrdersSQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := MakeJSONSchema(tables[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Schema      string                            `json:"$schema"`
+		Title       string                            `json:"title"`
+		Description string                            `json:"description"`
+		Properties  map[string]map[string]interface{} `json:"properties"`
+		Required    []string                          `json:"required"`
+	}
+	if err = json.Unmarshal([]byte(doc), &schema); err != nil {
+		t.Fatal(err, doc)
+	}
+	if schema.Schema != jsonSchemaDraft || schema.Title != "Orders" || schema.Description != "订单" {
+		t.Errorf("unexpected schema header:\n%s", doc)
+	}
+	if want := []string{"id", "tags", "amount", "birthday"}; !reflect.DeepEqual(schema.Required, want) {
+		t.Errorf("required = %v, want %v", schema.Required, want)
+	}
+	for name, want := range map[string]string{
+		"id":       `{"format":"int64","minimum":0,"readOnly":true,"type":"integer"}`,
+		"status":   `{"description":"order status","enum":["new","paid",null],"type":["string","null"]}`,
+		"tags":     `{"items":{"enum":["gift","urgent"],"type":"string"},"type":"array","uniqueItems":true}`,
+		"note":     `{"maxLength":255,"type":["string","null"]}`,
+		"amount":   `{"format":"decimal","type":"string"}`,
+		"birthday": `{"format":"date","type":"string"}`,
+		"paid_at":  `{"format":"date-time","type":["string","null"]}`,
+	} {
+		if got, _ := json.Marshal(schema.Properties[name]); string(got) != want {
+			t.Errorf("property %s = %s, want %s", name, got, want)
+		}
+	}
+	if !strings.Contains(doc, `"properties": {`+"\n    \"id\": {") {
+		t.Errorf("properties should keep the column order:\n%s", doc)
+	}
+}
+
+func TestMakeOpenAPI(t *testing.T) {
+	tables, err := GetTablesFromSQL(schemaOrdersSQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := MakeOpenAPI(tables[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"# Code generated by gmodel.\ncomponents:\n  schemas:\n    Orders:\n      description: 订单\n      type: object\n      properties:\n",
+		"        status:\n          type: string\n          nullable: true\n          enum:\n            - new\n            - paid\n            - null\n          description: order status\n",
+		"        note:\n          type: string\n          nullable: true\n          maxLength: 255\n",
+		"        paid_at:\n          type: string\n          format: date-time\n          nullable: true\n",
+		"      required:\n        - id\n        - tags\n",
+	} {
+		if !strings.Contains(doc, s) {
+			t.Errorf("openapi should contain %q:\n%s", s, doc)
+		}
+	}
+}
+
+func TestMakeOpenAPIQuoting(t *testing.T) {
+	tables, err := GetTablesFromSQL("CREATE TABLE `tricky` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `a: b` varchar(8) NOT NULL COMMENT '#1: - note',\n" +
+		"  `code` enum('0123','yes','#tag','-x','a: b','') NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := MakeOpenAPI(tables[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// read back, the keys and strings keep their value and type
+	var read struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					Description string        `yaml:"description"`
+					Enum        []interface{} `yaml:"enum"`
+				} `yaml:"properties"`
+			} `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	if err = yaml.Unmarshal([]byte(doc), &read); err != nil {
+		t.Fatalf("%v:\n%s", err, doc)
+	}
+	props := read.Components.Schemas["Tricky"].Properties
+	if props["a: b"].Description != "#1: - note" {
+		t.Errorf("property a: b = %+v:\n%s", props["a: b"], doc)
+	}
+	want := []interface{}{"0123", "yes", "#tag", "-x", "a: b", ""}
+	if !reflect.DeepEqual(props["code"].Enum, want) {
+		t.Errorf("enum = %#v, want %#v:\n%s", props["code"].Enum, want, doc)
+	}
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"path"
+	"strings"
+)
+
+// TypeMapping forces the go type of the columns it matches, a Column match wins over a SQLType match.
+type TypeMapping struct {
+	// SQLType matches the column type as the database prints it, e.g. tinyint(1),
+	// or its name without length and unsigned, e.g. decimal
+	SQLType string `json:"-" mapstructure:"sql_type"`
+	// Column is a table.column glob, e.g. orders.meta or *.created_at
+	Column string `json:"-" mapstructure:"column"`
+	GoType string `json:"-" mapstructure:"go_type"`
+	Import string `json:"-" mapstructure:"import"` // import path GoType needs, e.g. gorm.io/datatypes
+}
+
+// matchColumn .
+func (m TypeMapping) matchColumn(table, column string) bool {
+	if m.Column == "" {
+		return false
+	}
+	ok, _ := path.Match(strings.ToLower(m.Column), strings.ToLower(table+"."+column))
+	return ok
+}
+
+// matchSQLType .
+func (m TypeMapping) matchSQLType(sqlType string) bool {
+	if m.SQLType == "" || m.Column != "" {
+		return false
+	}
+	want := strings.ToLower(strings.TrimSpace(m.SQLType))
+	sqlType = strings.ToLower(sqlType)
+	return want == sqlType || want == sqlTypeName(sqlType)
+}
+
+// sqlTypeName strips the length and unsigned of sqlType, decimal(10,2) unsigned is decimal.
+func sqlTypeName(sqlType string) string {
+	if i := strings.Index(sqlType, "("); i >= 0 {
+		sqlType = sqlType[:i]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(sqlType, " unsigned"))
+}
+
+// mappedGoType returns the go type forced by the type mapping for column col of table,
+// a nullable column gets a pointer with NullInPointer, other null styles keep the mapped type.
+func mappedGoType(mappings []TypeMapping, table string, col *Column, style NullStyle) (name, path string, ok bool) {
+	var found *TypeMapping
+	for i := range mappings {
+		if mappings[i].matchColumn(table, col.Name) {
+			found = &mappings[i]
+			break
+		}
+	}
+	if found == nil {
+		for i := range mappings {
+			if mappings[i].matchSQLType(col.SQLType) {
+				found = &mappings[i]
+				break
+			}
+		}
+	}
+	if found == nil || found.GoType == "" {
+		return "", "", false
+	}
+
+	name = found.GoType
+	if style == NullInPointer && !strings.HasPrefix(name, "*") && !strings.HasPrefix(name, "[]") && !strings.HasPrefix(name, "map[") {
+		name = "*" + name
+	}
+	return name, found.Import, true
+}
@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"bytes"
+	goast "go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// CustomBegin starts a region of a generated file which is kept when the file is regenerated.
+	CustomBegin = "// gmodel:begin custom"
+
+	// CustomEnd ends the region started by CustomBegin.
+	CustomEnd = "// gmodel:end"
+
+	// KeepTag is the struct tag of a hand-written field kept when the file is regenerated.
+	KeepTag = `gmodel:"keep"`
+)
+
+// MergeCode keeps the hand-written parts of old in the regenerated code: the custom regions are
+// appended, the fields tagged gmodel:"keep" are put back into their structs, and the imports
+// the kept code refers to are added.
+func MergeCode(old, code []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	oldFile, err := goparser.ParseFile(fset, "old.go", old, goparser.ParseComments)
+	if err != nil {
+		return nil, errors.WithMessage(err, "parse existing file error")
+	}
+	regions, err := customRegions(old)
+	if err != nil {
+		return nil, err
+	}
+	keepFields := keptFields(fset, oldFile, old)
+	if len(regions) == 0 && len(keepFields) == 0 {
+		return code, nil
+	}
+
+	newFile, err := goparser.ParseFile(fset, "new.go", code, goparser.ParseComments)
+	if err != nil {
+		return nil, errors.WithMessage(err, "parse generated code error")
+	}
+	base := fset.File(newFile.Pos()).Base()
+
+	// insertions are applied from the end so the offsets stay valid
+	type insertion struct {
+		offset int
+		text   string
+	}
+	var inserts []insertion
+	kept := strings.Join(regions, "\n")
+	goast.Inspect(newFile, func(n goast.Node) bool {
+		ts, ok := n.(*goast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := ts.Type.(*goast.StructType)
+		if !ok || len(keepFields[ts.Name.Name]) == 0 {
+			return false
+		}
+		existing := make(map[string]bool)
+		for _, field := range st.Fields.List {
+			for _, name := range field.Names {
+				existing[name.Name] = true
+			}
+		}
+		var text strings.Builder
+		for _, field := range keepFields[ts.Name.Name] {
+			if !existing[field.name] {
+				text.WriteString("\n" + field.text)
+				kept += "\n" + field.text
+			}
+		}
+		if text.Len() > 0 {
+			inserts = append(inserts, insertion{offset: int(st.Fields.Closing) - base, text: text.String() + "\n"})
+		}
+		return false
+	})
+
+	if imports := missingImports(oldFile, newFile, kept); len(imports) > 0 {
+		specs := "\n\t" + strings.Join(imports, "\n\t")
+		if decl := importDecl(newFile); decl != nil && decl.Lparen.IsValid() {
+			inserts = append(inserts, insertion{offset: int(decl.Lparen) - base + 1, text: specs})
+		} else {
+			inserts = append(inserts, insertion{offset: int(newFile.Name.End()) - base, text: "\n\nimport (" + specs + "\n)"})
+		}
+	}
+	sort.SliceStable(inserts, func(i, j int) bool {
+		return inserts[i].offset > inserts[j].offset
+	})
+
+	merged := append([]byte(nil), code...)
+	for _, ins := range inserts {
+		merged = append(merged[:ins.offset], append([]byte(ins.text), merged[ins.offset:]...)...)
+	}
+	if len(regions) > 0 {
+		merged = append(bytes.TrimRight(merged, "\n"), []byte("\n\n"+strings.Join(regions, "\n\n")+"\n")...)
+	}
+
+	formatted, err := format.Source(merged)
+	if err != nil {
+		return merged, errors.WithMessage(err, "format merged code error")
+	}
+	return formatted, nil
+}
+
+// customRegions returns the text of the regions between CustomBegin and CustomEnd, markers included.
+func customRegions(src []byte) ([]string, error) {
+	var regions []string
+	var region []string
+	for _, line := range strings.Split(string(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case region == nil && strings.HasPrefix(trimmed, CustomBegin):
+			region = []string{line}
+		case region != nil && strings.HasPrefix(trimmed, CustomEnd):
+			regions = append(regions, strings.Join(append(region, line), "\n"))
+			region = nil
+		case region != nil:
+			region = append(region, line)
+		}
+	}
+	if region != nil {
+		return nil, errors.Errorf("%q without %q", CustomBegin, CustomEnd)
+	}
+	return regions, nil
+}
+
+// keptField .
+type keptField struct {
+	name string
+	text string // source of the field with its comments
+}
+
+// keptFields returns the fields tagged gmodel:"keep" by struct name.
+func keptFields(fset *token.FileSet, file *goast.File, src []byte) map[string][]keptField {
+	fields := make(map[string][]keptField)
+	base := fset.File(file.Pos()).Base()
+	goast.Inspect(file, func(n goast.Node) bool {
+		ts, ok := n.(*goast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := ts.Type.(*goast.StructType)
+		if !ok {
+			return false
+		}
+		for _, field := range st.Fields.List {
+			if field.Tag == nil || len(field.Names) == 0 {
+				continue
+			}
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil || reflect.StructTag(tag).Get("gmodel") != "keep" {
+				continue
+			}
+			start, end := field.Pos(), field.End()
+			if field.Doc != nil {
+				start = field.Doc.Pos()
+			}
+			if field.Comment != nil {
+				end = field.Comment.End()
+			}
+			fields[ts.Name.Name] = append(fields[ts.Name.Name], keptField{
+				name: field.Names[0].Name,
+				text: string(src[int(start)-base : int(end)-base]),
+			})
+		}
+		return false
+	})
+	return fields
+}
+
+// selectorRe matches the package qualifiers of kept code, e.g. json in json.RawMessage.
+var selectorRe = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\.[A-Za-z_]`)
+
+// missingImports returns the import specs of old which kept refers to and code doesn't import.
+func missingImports(old, code *goast.File, kept string) []string {
+	used := make(map[string]bool)
+	for _, match := range selectorRe.FindAllStringSubmatch(kept, -1) {
+		used[match[1]] = true
+	}
+	imported := make(map[string]bool)
+	for _, spec := range code.Imports {
+		imported[spec.Path.Value] = true
+	}
+
+	var specs []string
+	for _, spec := range old.Imports {
+		if imported[spec.Path.Value] {
+			continue
+		}
+		p, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if !used[name] {
+			continue
+		}
+		if spec.Name != nil {
+			specs = append(specs, spec.Name.Name+" "+spec.Path.Value)
+		} else {
+			specs = append(specs, spec.Path.Value)
+		}
+	}
+	return specs
+}
+
+// importDecl .
+func importDecl(file *goast.File) *goast.GenDecl {
+	for _, decl := range file.Decls {
+		if d, ok := decl.(*goast.GenDecl); ok && d.Tok == token.IMPORT {
+			return d
+		}
+	}
+	return nil
+}
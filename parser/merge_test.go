@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeCode(t *testing.T) {
+	const old = `package model
+
+import (
+	"encoding/json"
+	stdtime "time"
+)
+
+// Users  .
+type Users struct {
+	ID   int    ` + "`gorm:\"column:id;primaryKey\"`" + `
+	Name string ` + "`gorm:\"column:name\"`" + `
+	// Extra is filled by the service
+	Extra json.RawMessage ` + "`gorm:\"-\" gmodel:\"keep\"`" + ` // not a column
+	Temp  string          ` + "`gorm:\"-\"`" + `
+}
+
+// gmodel:begin custom
+
+// Expired .
+func (m *Users) Expired() bool {
+	return stdtime.Now().IsZero()
+}
+
+// gmodel:end
+`
+	code, err := ParseSQL("CREATE TABLE `users` (`id` int NOT NULL, `email` varchar(64) NOT NULL, PRIMARY KEY (`id`));")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generated strings.Builder
+	if err = writeModelCodes(code, &generated); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := MergeCode([]byte(old), []byte(generated.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(merged)
+	for _, s := range []string{
+		`"encoding/json"`,
+		`stdtime "time"`,
+		"Email string `gorm:\"column:email;NOT NULL\"`",
+		"// Extra is filled by the service\n\tExtra json.RawMessage `gorm:\"-\" gmodel:\"keep\"` // not a column\n}",
+		"// gmodel:begin custom\n\n// Expired .\nfunc (m *Users) Expired() bool {",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("%q not found in:\n%s", s, got)
+		}
+	}
+	for _, s := range []string{"Temp", "Name string"} {
+		if strings.Contains(got, s) {
+			t.Errorf("%q should not be kept:\n%s", s, got)
+		}
+	}
+
+	if _, err = MergeCode([]byte("package model\n\n// gmodel:begin custom\n"), []byte(generated.String())); err == nil {
+		t.Error("unterminated custom region should fail")
+	}
+}
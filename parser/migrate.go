@@ -0,0 +1,256 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Migration is the mysql DDL moving the database to the models (Up) and back (Down).
+type Migration struct {
+	Up   []string `json:"-"`
+	Down []string `json:"-"`
+}
+
+// MigrateModels compares models with tables read from the database, tables without a model are left alone.
+// The column type comes from the gorm tag; without it the column keeps its type in the database when the go type
+// is the one gmodel writes for it with options, the options the models are generated with.
+// The models of views are skipped, a view is not created by ALTER or CREATE TABLE.
+func MigrateModels(models []*ModelStruct, tables []*Table, options ...Option) (Migration, error) {
+	opt := parseOption(options)
+	var m Migration
+	var down [][]string
+	for _, model := range models {
+		if model.View {
+			continue
+		}
+		from := schemaTable(tables, model.Table.Name)
+		to, err := modelTable(model, from, opt)
+		if err != nil {
+			return Migration{}, err
+		}
+		if from == nil {
+			m.Up = append(m.Up, createTableSQL(to))
+			down = append(down, []string{"DROP TABLE " + quoteName(to.Name) + ";"})
+			continue
+		}
+		m.Up = append(m.Up, alterTableSQL(from, to)...)
+		down = append(down, alterTableSQL(to, from))
+	}
+	// tables are restored in reverse order
+	for i := len(down) - 1; i >= 0; i-- {
+		m.Down = append(m.Down, down[i]...)
+	}
+	return m, nil
+}
+
+// modelTable copies the table of model with the column types resolved and unique columns as indexes.
+// A column without type in the gorm tag keeps the type of the column of from when its go type is unchanged,
+// as DiffModel compares them; a go type alone can't tell varchar(255) from text, it's never guessed.
+func modelTable(model *ModelStruct, from *Table, opt options) (*Table, error) {
+	table := model.Table.clone()
+	var goTypes map[string]string
+	if from != nil {
+		data, _ := makeTmplData(from, opt)
+		goTypes = make(map[string]string, len(data.Fields))
+		for _, f := range data.Fields {
+			goTypes[f.ColumnName] = f.GoType
+		}
+	}
+	for _, col := range table.Columns {
+		if col.SQLType != "" {
+			continue
+		}
+		goType := model.GoTypes[col.Name]
+		if from != nil {
+			if i := from.column(col.Name); i >= 0 && goTypes[from.Columns[i].Name] == goType {
+				col.SQLType = from.Columns[i].SQLType
+				continue
+			}
+		}
+		return nil, errors.Errorf("%s.%s: the gorm tag has no type and %s is not the go type of the column, "+
+			"generate the models with --with-type", model.Name, col.Name, goType)
+	}
+	return table, nil
+}
+
+// alterTableSQL returns the statements turning from into to, indexes are dropped first and added last.
+func alterTableSQL(from, to *Table) []string {
+	alter := "ALTER TABLE " + quoteName(to.Name) + " "
+	var stmts []string
+
+	fromIndexes, toIndexes := tableIndexes(from), tableIndexes(to)
+	for _, index := range fromIndexes {
+		if other := matchIndex(toIndexes, index); other == nil || indexString(other) != indexString(index) {
+			stmts = append(stmts, alter+"DROP INDEX "+quoteName(index.Name)+";")
+		}
+	}
+	fromPK, toPK := primaryKeyColumns(from), primaryKeyColumns(to)
+	if fromPK != "" && !strings.EqualFold(fromPK, toPK) {
+		stmts = append(stmts, alter+"DROP PRIMARY KEY;")
+	}
+
+	for i, col := range to.Columns {
+		position := " FIRST"
+		if i > 0 {
+			position = " AFTER " + quoteName(to.Columns[i-1].Name)
+		}
+		j := from.column(col.Name)
+		switch {
+		case j < 0:
+			stmts = append(stmts, alter+"ADD COLUMN "+columnSQL(col, "")+position+";")
+		case columnChanged(from.Columns[j], col):
+			stmts = append(stmts, alter+"MODIFY COLUMN "+columnSQL(col, from.Columns[j].Comment)+";")
+		}
+	}
+	for _, col := range from.Columns {
+		if to.column(col.Name) < 0 {
+			stmts = append(stmts, alter+"DROP COLUMN "+quoteName(col.Name)+";")
+		}
+	}
+
+	if toPK != "" && !strings.EqualFold(fromPK, toPK) {
+		stmts = append(stmts, alter+"ADD PRIMARY KEY ("+quoteNames(primaryKeys(to))+");")
+	}
+	for _, index := range toIndexes {
+		if other := matchIndex(fromIndexes, index); other == nil || indexString(other) != indexString(index) {
+			stmts = append(stmts, alter+"ADD "+indexSQL(index)+";")
+		}
+	}
+	return stmts
+}
+
+// createTableSQL .
+func createTableSQL(t *Table) string {
+	lines := make([]string, 0, len(t.Columns)+len(t.Indexes)+1)
+	for _, col := range t.Columns {
+		lines = append(lines, "  "+columnSQL(col, ""))
+	}
+	if pk := primaryKeys(t); len(pk) > 0 {
+		lines = append(lines, "  PRIMARY KEY ("+quoteNames(pk)+")")
+	}
+	for _, index := range tableIndexes(t) {
+		lines = append(lines, "  "+indexSQL(index))
+	}
+	return "CREATE TABLE " + quoteName(t.Name) + " (\n" + strings.Join(lines, ",\n") + "\n);"
+}
+
+// tableIndexes returns the secondary indexes with the unique columns as single column unique indexes.
+func tableIndexes(t *Table) []*Index {
+	indexes := append([]*Index(nil), t.Indexes...)
+	for _, col := range t.Columns {
+		if col.Unique && matchIndex(indexes, &Index{Name: col.Name, Unique: true, Columns: []string{col.Name}}) == nil {
+			indexes = append(indexes, &Index{Name: col.Name, Unique: true, Columns: []string{col.Name}})
+		}
+	}
+	return indexes
+}
+
+// matchIndex finds index by name, a single column unique index also matches one of another name on the same column.
+func matchIndex(indexes []*Index, index *Index) *Index {
+	for _, other := range indexes {
+		if strings.EqualFold(other.Name, index.Name) {
+			return other
+		}
+	}
+	if isUniqueColumnIndex(index) {
+		for _, other := range indexes {
+			if isUniqueColumnIndex(other) && strings.EqualFold(other.Columns[0], index.Columns[0]) {
+				return &Index{Name: index.Name, Unique: true, Columns: other.Columns}
+			}
+		}
+	}
+	return nil
+}
+
+// columnChanged .
+func columnChanged(from, to *Column) bool {
+	return normalizeSQLType(from.SQLType) != normalizeSQLType(to.SQLType) ||
+		(from.NotNull || from.PrimaryKey) != (to.NotNull || to.PrimaryKey) ||
+		from.AutoIncrement != to.AutoIncrement ||
+		from.Default != to.Default
+}
+
+// columnSQL is the column definition, comment is used when col has none.
+func columnSQL(col *Column, comment string) string {
+	var b strings.Builder
+	b.WriteString(quoteName(col.Name))
+	b.WriteString(" ")
+	b.WriteString(col.SQLType)
+	if col.NotNull || col.PrimaryKey {
+		b.WriteString(" NOT NULL")
+	} else {
+		b.WriteString(" NULL")
+	}
+	if col.AutoIncrement {
+		b.WriteString(" AUTO_INCREMENT")
+	}
+	if col.Default != "" {
+		b.WriteString(" DEFAULT ")
+		b.WriteString(defaultSQL(col.Default))
+	}
+	if col.Comment != "" {
+		comment = col.Comment
+	}
+	if comment != "" {
+		b.WriteString(" COMMENT ")
+		b.WriteString(quoteString(comment))
+	}
+	return b.String()
+}
+
+// indexSQL .
+func indexSQL(index *Index) string {
+	kind := "INDEX "
+	switch {
+	case index.Unique:
+		kind = "UNIQUE INDEX "
+	case index.Fulltext:
+		kind = "FULLTEXT INDEX "
+	}
+	return kind + quoteName(index.Name) + " (" + quoteNames(index.Columns) + ")"
+}
+
+// defaultSQL quotes a default value unless it's a number or a function such as CURRENT_TIMESTAMP.
+func defaultSQL(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	switch upper := strings.ToUpper(value); {
+	case upper == "NULL", strings.HasPrefix(upper, "CURRENT_TIMESTAMP"), strings.HasSuffix(value, ")"):
+		return value
+	}
+	return quoteString(value)
+}
+
+// primaryKeys .
+func primaryKeys(t *Table) []string {
+	var cols []string
+	for _, col := range t.Columns {
+		if col.PrimaryKey {
+			cols = append(cols, col.Name)
+		}
+	}
+	return cols
+}
+
+// quoteName .
+func quoteName(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// quoteNames .
+func quoteNames(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, quoteName(name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteString .
+func quoteString(s string) string {
+	return fmt.Sprintf("'%s'", strings.Replace(strings.Replace(s, `\`, `\\`, -1), "'", "''", -1))
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeModels generates the models of ddl into a temp dir and reads them back.
+func writeModels(t *testing.T, ddl string, options ...Option) []*ModelStruct {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := ParseSQLToWrite(ddl, buf, options...); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	models, err := ParseModelDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return models
+}
+
+func TestMigrateModels(t *testing.T) {
+	const dbDDL = "CREATE TABLE `orders` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` int NOT NULL,\n" +
+		"  `note` varchar(64) NOT NULL DEFAULT '' COMMENT 'note',\n" +
+		"  `legacy` int NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `idx_user` (`user_id`)\n" +
+		");"
+	const modelDDL = "CREATE TABLE `orders` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` bigint NOT NULL,\n" +
+		"  `amount` decimal(10,2) NOT NULL DEFAULT '0.00',\n" +
+		"  `note` varchar(64) NOT NULL DEFAULT '' COMMENT 'note',\n" +
+		"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_user_created` (`user_id`, `created_at`)\n" +
+		");\n" +
+		"CREATE TABLE `refunds` (`id` int NOT NULL, `reason` varchar(32) NOT NULL UNIQUE, PRIMARY KEY (`id`));"
+
+	models := writeModels(t, modelDDL, WithGormType())
+	dbTables, _ := GetTablesFromSQL(dbDDL)
+	m, err := MigrateModels(models, dbTables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"ALTER TABLE `orders` DROP INDEX `idx_user`;",
+		"ALTER TABLE `orders` MODIFY COLUMN `user_id` bigint(20) NOT NULL;",
+		"ALTER TABLE `orders` ADD COLUMN `amount` decimal(10,2) NOT NULL DEFAULT 0.00 AFTER `user_id`;",
+		"ALTER TABLE `orders` ADD COLUMN `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP AFTER `note`;",
+		"ALTER TABLE `orders` DROP COLUMN `legacy`;",
+		"ALTER TABLE `orders` ADD UNIQUE INDEX `uk_user_created` (`user_id`, `created_at`);",
+		"CREATE TABLE `refunds` (\n" +
+			"  `id` int(11) NOT NULL,\n" +
+			"  `reason` varchar(32) NOT NULL,\n" +
+			"  PRIMARY KEY (`id`),\n" +
+			"  UNIQUE INDEX `reason` (`reason`)\n" +
+			");",
+	}
+	if got := strings.Join(m.Up, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("unexpected up:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+
+	// up brings the database to the models, down brings it back
+	schema := NewSchema()
+	if err = schema.Apply(dbDDL + strings.Join(m.Up, "\n")); err != nil {
+		t.Fatal(err)
+	}
+	for _, model := range models {
+		if diff := DiffModel(model, schema.Table(model.Table.Name)); len(diff) != 0 {
+			t.Errorf("%s drifted after up: %v", model.Table.Name, diff)
+		}
+	}
+	if err = schema.Apply(strings.Join(m.Down, "\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Tables()) != 1 {
+		t.Fatalf("refunds should be dropped by down: %v", schema.Tables())
+	}
+	for _, model := range writeModels(t, dbDDL, WithGormType()) {
+		if diff := DiffModel(model, schema.Table(model.Table.Name)); len(diff) != 0 {
+			t.Errorf("%s drifted after down: %v", model.Table.Name, diff)
+		}
+	}
+
+	if _, err = MigrateModels(writeModels(t, dbDDL), dbTables); err != nil {
+		t.Errorf("unchanged go types should keep the column types: %s", err)
+	}
+}
+
+func TestMigrateModelsWithoutType(t *testing.T) {
+	const ddl = "CREATE TABLE `posts` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `title` text NOT NULL,\n" +
+		"  `body` mediumtext NULL,\n" +
+		"  `status` enum('draft','published') NOT NULL DEFAULT 'draft',\n" +
+		"  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");"
+	dbTables, _ := GetTablesFromSQL(ddl)
+
+	// the models without type keep the types of the database
+	m, err := MigrateModels(writeModels(t, ddl), dbTables)
+	if err != nil || len(m.Up) != 0 || len(m.Down) != 0 {
+		t.Errorf("unchanged models should not migrate: %v, %v", m, err)
+	}
+
+	// a changed or new column needs the type tag
+	for _, modelDDL := range []string{
+		strings.Replace(ddl, "`title` text NOT NULL", "`title` bigint NOT NULL", 1),
+		strings.Replace(ddl, "  PRIMARY KEY", "  `summary` varchar(64) NOT NULL,\n  PRIMARY KEY", 1),
+	} {
+		if _, err = MigrateModels(writeModels(t, modelDDL), dbTables); err == nil || !strings.Contains(err.Error(), "--with-type") {
+			t.Errorf("a column without type tag should ask for --with-type, got %v", err)
+		}
+	}
+	if _, err = MigrateModels(writeModels(t, ddl), nil); err == nil {
+		t.Error("a new table without type tags should fail")
+	}
+}
@@ -0,0 +1,248 @@
+package parser
+
+import (
+	goast "go/ast"
+	goparser "go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/inflection"
+	"github.com/pkg/errors"
+)
+
+// ModelStruct is a model struct read back from a go file, its table is rebuilt from the gorm tags.
+type ModelStruct struct {
+	Name string `json:"-"` // go struct name
+	File string `json:"-"`
+	// Table holds the columns and indexes of the gorm tags, Column.Tp is nil,
+	// Column.SQLType is only set when the tag has a type and Column.Comment is the field comment
+	Table *Table `json:"-"`
+	// GoTypes are the field types by column name
+	GoTypes map[string]string `json:"-"`
+	// View is set when every field is read-only (gorm "->"), the model of a view
+	View bool `json:"-"`
+}
+
+// ParseModelDir reads the structs with gorm column tags of the go files in dir, test files are skipped.
+// The table name is the one returned by TableName(), or the gorm default (snake case plural) without it.
+func ParseModelDir(dir string) ([]*ModelStruct, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	fset := token.NewFileSet()
+	var models []*ModelStruct
+	tableNames := make(map[string]string)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := goparser.ParseFile(fset, file, nil, goparser.ParseComments)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "parse %s", file)
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *goast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*goast.TypeSpec)
+					if !ok {
+						continue
+					}
+					st, ok := ts.Type.(*goast.StructType)
+					if !ok {
+						continue
+					}
+					if model := parseModelStruct(fset, ts.Name.Name, st); model != nil {
+						model.File = file
+						models = append(models, model)
+					}
+				}
+			case *goast.FuncDecl:
+				if recv, name, ok := tableNameFunc(d); ok {
+					tableNames[recv] = name
+				}
+			}
+		}
+	}
+
+	for _, model := range models {
+		if name, ok := tableNames[model.Name]; ok {
+			model.Table.Name = name
+		} else {
+			model.Table.Name = inflection.Plural(toSnake(model.Name))
+		}
+		for _, index := range model.Table.Indexes {
+			if index.Name == "" {
+				index.Name = "idx_" + model.Table.Name + "_" + strings.Join(index.Columns, "_")
+			}
+		}
+	}
+	return models, nil
+}
+
+// parseModelStruct returns nil if no field has a gorm column tag.
+func parseModelStruct(fset *token.FileSet, name string, st *goast.StructType) *ModelStruct {
+	model := &ModelStruct{
+		Name:    name,
+		Table:   &Table{},
+		GoTypes: make(map[string]string),
+	}
+	type indexColumn struct {
+		column   string
+		priority int
+	}
+	indexColumns := make(map[string][]indexColumn)
+	readOnly := 0
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 || field.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		gormTag := reflect.StructTag(tag).Get("gorm")
+		col, indexes := parseGormTag(gormTag)
+		if col == nil {
+			continue
+		}
+		if readOnlyTag(gormTag) {
+			readOnly++
+		}
+		var typ strings.Builder
+		_ = printer.Fprint(&typ, fset, field.Type)
+		model.GoTypes[col.Name] = typ.String()
+		if field.Comment != nil {
+			col.Comment = strings.TrimSpace(field.Comment.Text())
+		}
+		model.Table.Columns = append(model.Table.Columns, col)
+
+		for _, index := range indexes {
+			// an unnamed index is named by gorm after the table, see ParseModelDir
+			if index.Name == "" {
+				model.Table.Indexes = append(model.Table.Indexes,
+					&Index{Unique: index.Unique, Fulltext: index.Fulltext, Columns: []string{col.Name}})
+				continue
+			}
+			if model.Table.index(index.Name) < 0 {
+				model.Table.Indexes = append(model.Table.Indexes, &Index{Name: index.Name, Unique: index.Unique, Fulltext: index.Fulltext})
+			}
+			indexColumns[index.Name] = append(indexColumns[index.Name], indexColumn{column: col.Name, priority: index.priority})
+		}
+	}
+	if len(model.Table.Columns) == 0 {
+		return nil
+	}
+	model.View = readOnly == len(model.Table.Columns)
+	model.Table.View = model.View
+
+	for _, index := range model.Table.Indexes {
+		cols := indexColumns[index.Name]
+		sort.SliceStable(cols, func(i, j int) bool {
+			return cols[i].priority < cols[j].priority
+		})
+		for _, c := range cols {
+			index.Columns = append(index.Columns, c.column)
+		}
+	}
+	return model
+}
+
+// tagIndex is an index setting of a gorm tag.
+type tagIndex struct {
+	Index
+	priority int
+}
+
+// parseGormTag reads the settings gmodel writes, nil if there is no column.
+func parseGormTag(tag string) (*Column, []tagIndex) {
+	col := &Column{}
+	var indexes []tagIndex
+	for _, setting := range strings.Split(tag, ";") {
+		key, value := setting, ""
+		if i := strings.Index(setting, ":"); i >= 0 {
+			key, value = setting[:i], setting[i+1:]
+		}
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "COLUMN":
+			col.Name = value
+		case "TYPE":
+			col.SQLType = value
+		case "PRIMARYKEY", "PRIMARY_KEY":
+			col.PrimaryKey = true
+		case "AUTOINCREMENT", "AUTO_INCREMENT":
+			col.AutoIncrement = true
+		case "DEFAULT":
+			col.Default = value
+		case "UNIQUE":
+			col.Unique = true
+		case "NOT NULL":
+			col.NotNull = true
+		case "INDEX", "UNIQUEINDEX":
+			index := tagIndex{priority: 10}
+			index.Unique = strings.EqualFold(strings.TrimSpace(key), "uniqueIndex")
+			for i, part := range strings.Split(value, ",") {
+				switch {
+				case i == 0:
+					index.Name = part
+				case strings.HasPrefix(strings.ToLower(part), "priority:"):
+					index.priority, _ = strconv.Atoi(part[len("priority:"):])
+				case strings.EqualFold(part, "class:FULLTEXT"):
+					index.Fulltext = true
+				case strings.EqualFold(part, "unique"):
+					index.Unique = true
+				}
+			}
+			indexes = append(indexes, index)
+		}
+	}
+	if col.Name == "" || tag == "-" {
+		return nil, nil
+	}
+	col.NotNull = col.NotNull || col.PrimaryKey
+	col.Nullable = !col.NotNull
+	return col, indexes
+}
+
+// readOnlyTag reports whether the gorm tag has the read-only permission "->".
+func readOnlyTag(tag string) bool {
+	for _, setting := range strings.Split(tag, ";") {
+		if strings.TrimSpace(setting) == "->" {
+			return true
+		}
+	}
+	return false
+}
+
+// tableNameFunc matches func (m *X) TableName() string { return "name" }.
+func tableNameFunc(d *goast.FuncDecl) (recv, name string, ok bool) {
+	if d.Name.Name != "TableName" || d.Recv == nil || len(d.Recv.List) != 1 || d.Body == nil || len(d.Body.List) != 1 {
+		return "", "", false
+	}
+	typ := d.Recv.List[0].Type
+	if star, isStar := typ.(*goast.StarExpr); isStar {
+		typ = star.X
+	}
+	ident, isIdent := typ.(*goast.Ident)
+	ret, isReturn := d.Body.List[0].(*goast.ReturnStmt)
+	if !isIdent || !isReturn || len(ret.Results) != 1 {
+		return "", "", false
+	}
+	lit, isLit := ret.Results[0].(*goast.BasicLit)
+	if !isLit || lit.Kind != token.STRING {
+		return "", "", false
+	}
+	name, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", false
+	}
+	return ident.Name, name, true
+}
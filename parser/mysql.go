@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"database/sql" // "database/sql"
 
 	_ "github.com/go-sql-driver/mysql" // "mysql"
@@ -14,21 +15,55 @@ func GetCreateTableFromDB(dsn, tableName string) (string, error) {
 		return "", errors.WithMessage(err, "open db error")
 	}
 	defer db.Close()
-	rows, err := db.Query("SHOW CREATE TABLE " + tableName)
+	createSQL, view, err := showCreateTable(context.Background(), db, tableName)
 	if err != nil {
-		return "", errors.WithMessage(err, "query show create table error")
+		return "", err
+	}
+	if view {
+		return "", errors.Errorf("%s is a view, it has no CREATE TABLE statement", tableName)
+	}
+	return createSQL, nil
+}
+
+// showCreateTable returns the CREATE TABLE statement, or the CREATE VIEW statement with view true.
+func showCreateTable(ctx context.Context, db *sql.DB, tableName string) (string, bool, error) {
+	rows, err := db.QueryContext(ctx, "SHOW CREATE TABLE "+tableName)
+	if err != nil {
+		return "", false, errors.WithMessage(err, "query show create table error")
 	}
 	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", false, err
+	}
 	if !rows.Next() {
-		return "", errors.Errorf("table(%s) not found", tableName)
+		return "", false, errors.Errorf("table(%s) not found", tableName)
+	}
+	// a view is printed as View, Create View, character_set_client, collation_connection
+	values := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err = rows.Scan(dest...); err != nil {
+		return "", false, err
 	}
-	var table string
-	var createSQL string
-	err = rows.Scan(&table, &createSQL)
+	if len(values) < 2 {
+		return "", false, errors.Errorf("unexpected show create table result of %s", tableName)
+	}
+	return values[1].String, cols[0] == "View", nil
+}
+
+// mysqlTable reads tableName by SHOW CREATE TABLE, the columns of a view are read from information_schema.
+func mysqlTable(ctx context.Context, db *sql.DB, tableName string, options ...Option) (*Table, error) {
+	createSQL, view, err := showCreateTable(ctx, db, tableName)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return createSQL, nil
+	if view {
+		return mysqlView(ctx, db, tableName, options...)
+	}
+	return parseCreateTable(createSQL, tableName, options...)
 }
 
 // getCreateTables .
@@ -38,22 +73,30 @@ func getCreateTablesByConfig(dsn string) ([]string, error) {
 		return nil, errors.WithMessage(err, "open db error")
 	}
 	defer db.Close()
-	rows, err := db.Query("SHOW TABLES")
+	// views are skipped, SHOW CREATE TABLE of a view is not a CREATE TABLE statement
+	tables, _, err := showFullTables(context.Background(), db)
+	return tables, err
+}
+
+// showFullTables lists the tables and the views of the database.
+func showFullTables(ctx context.Context, db *sql.DB) (tables []string, views []string, err error) {
+	rows, err := db.QueryContext(ctx, "SHOW FULL TABLES")
 	if err != nil {
-		return nil, errors.WithMessage(err, "query show  tables error")
+		return nil, nil, errors.WithMessage(err, "query show full tables error")
 	}
 	defer rows.Close()
-	var tables []string
 	for rows.Next() {
-		var table string
-		err = rows.Scan(&table)
-		if err != nil {
-			// handle this error
-			panic(err)
+		var name, tableType string
+		if err = rows.Scan(&name, &tableType); err != nil {
+			return nil, nil, err
+		}
+		if tableType == "VIEW" {
+			views = append(views, name)
+		} else {
+			tables = append(tables, name)
 		}
-		tables = append(tables, table)
 	}
-	return tables, nil
+	return tables, views, rows.Err()
 }
 
 // ParseSQLFromDB .
@@ -65,16 +108,7 @@ func ParseSQLFromDB(dsn, tableName string, options ...Option) (ModelCodes, error
 	return ParseSQL(createSQL, options...)
 }
 
-//GetCreateTables 获取待生成model的所有表
+// GetCreateTables 获取待生成model的所有表
 func GetCreateTables(dsn string, mysqlTable string) ([]string, error) {
-	if mysqlTable != "*" {
-		return []string{mysqlTable}, nil
-	}
-
-	tables, err := getCreateTablesByConfig(dsn)
-	if err != nil {
-		return nil, err
-	}
-
-	return tables, nil
+	return GetTables(DriverMySQL, dsn, mysqlTable)
 }
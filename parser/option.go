@@ -1,5 +1,9 @@
 package parser
 
+import (
+	"path/filepath"
+)
+
 // NullStyle .
 type NullStyle int
 
@@ -19,23 +23,43 @@ type Option func(*options)
 
 // options .
 type options struct {
-	Charset        string    `json:"-"`
-	Collation      string    `json:"-"`
-	JSONTag        bool      `json:"-"`
-	TablePrefix    string    `json:"-"`
-	ColumnPrefix   string    `json:"-"`
-	NoNullType     bool      `json:"-"`
-	NullStyle      NullStyle `json:"-"`
-	Package        string    `json:"-"`
-	GormType       bool      `json:"-"`
-	ForceTableName bool      `json:"-"`
-	JudgeUnsigned  bool      `json:"-"`
+	Charset        string        `json:"-"`
+	Collation      string        `json:"-"`
+	JSONTag        bool          `json:"-"`
+	TablePrefix    string        `json:"-"`
+	ColumnPrefix   string        `json:"-"`
+	NoNullType     bool          `json:"-"`
+	NullStyle      NullStyle     `json:"-"`
+	Package        string        `json:"-"`
+	GormType       bool          `json:"-"`
+	ForceTableName bool          `json:"-"`
+	JudgeUnsigned  bool          `json:"-"`
+	StructTemplate string        `json:"-"`
+	FileTemplate   string        `json:"-"`
+	TemplateDir    string        `json:"-"`
+	DaoPackage     string        `json:"-"`
+	ModelImport    string        `json:"-"`
+	Associations   bool          `json:"-"`
+	HasMany        bool          `json:"-"`
+	Schema         []*Table      `json:"-"`
+	TableNames     []string      `json:"-"`
+	TypeMapping    []TypeMapping `json:"-"`
+	ProtoGoPackage string        `json:"-"`
+	ConvertTarget  string        `json:"-"`
+	ConvertImport  string        `json:"-"`
+	ConvertPackage string        `json:"-"`
+	TSBigIntString bool          `json:"-"`
+	Flavor         Flavor        `json:"-"`
 }
 
 // defaultOptions .
 var defaultOptions = options{
-	NullStyle: NullInSQL,
-	Package:   "model",
+	NullStyle:  NullInSQL,
+	Package:    "model",
+	DaoPackage: "dao",
+
+	ConvertPackage: "convert",
+	Flavor:         FlavorGorm,
 }
 
 // WithCharset .
@@ -115,6 +139,121 @@ func WithJudgeUnsigned() Option {
 	}
 }
 
+// WithTemplateFile replaces the built-in struct and file templates by the template files,
+// an empty path keeps the built-in one.
+func WithTemplateFile(structFile, fileFile string) Option {
+	return func(o *options) {
+		if structFile != "" {
+			o.StructTemplate = structFile
+		}
+		if fileFile != "" {
+			o.FileTemplate = fileFile
+		}
+	}
+}
+
+// WithTemplateDir uses struct.tmpl and file.tmpl of dir if they exist, parsing fails if dir can't be read.
+func WithTemplateDir(dir string) Option {
+	return func(o *options) {
+		o.TemplateDir = dir
+		if p := filepath.Join(dir, StructTemplateName); fileExists(p) {
+			o.StructTemplate = p
+		}
+		if p := filepath.Join(dir, FileTemplateName); fileExists(p) {
+			o.FileTemplate = p
+		}
+	}
+}
+
+// WithDaoPackage sets the package name of the generated repositories.
+func WithDaoPackage(pkg string) Option {
+	return func(o *options) {
+		o.DaoPackage = pkg
+	}
+}
+
+// WithModelImport sets the import path of the model package the repositories use.
+func WithModelImport(path string) Option {
+	return func(o *options) {
+		o.ModelImport = path
+	}
+}
+
+// WithAssociations turns foreign keys into BelongsTo fields on the child struct.
+func WithAssociations() Option {
+	return func(o *options) {
+		o.Associations = true
+	}
+}
+
+// WithHasMany also writes HasMany slices on the parent struct, it implies WithAssociations.
+func WithHasMany() Option {
+	return func(o *options) {
+		o.Associations = true
+		o.HasMany = true
+	}
+}
+
+// WithSchema sets all tables of the schema the associations are resolved against,
+// the tables being parsed are used if it is not set.
+func WithSchema(tables []*Table) Option {
+	return func(o *options) {
+		o.Schema = tables
+	}
+}
+
+// WithTableNames sets the names of all tables of the database, the enum types are named after the struct
+// names of all of them so a table selected alone gets the same names; the tables of WithSchema are used if not set.
+func WithTableNames(names ...string) Option {
+	return func(o *options) {
+		o.TableNames = names
+	}
+}
+
+// WithTypeMapping forces the go types of the matched columns, it's consulted before the built-in type mapping.
+func WithTypeMapping(mappings ...TypeMapping) Option {
+	return func(o *options) {
+		o.TypeMapping = append(o.TypeMapping, mappings...)
+	}
+}
+
+// WithProtoGoPackage writes option go_package in the proto files.
+func WithProtoGoPackage(pkg string) Option {
+	return func(o *options) {
+		o.ProtoGoPackage = pkg
+	}
+}
+
+// WithConvert sets the target of the converters, ConvertPB or ConvertDTO, and the import path of its package.
+func WithConvert(target, importPath string) Option {
+	return func(o *options) {
+		o.ConvertTarget = target
+		o.ConvertImport = importPath
+	}
+}
+
+// WithConvertPackage sets the package name of the generated converters.
+func WithConvertPackage(pkg string) Option {
+	return func(o *options) {
+		o.ConvertPackage = pkg
+	}
+}
+
+// WithTSBigIntString writes the int64 columns as string in the TypeScript interfaces and adds
+// the ,string option to their json tags in the models, since a number loses precision beyond 2^53.
+func WithTSBigIntString() Option {
+	return func(o *options) {
+		o.TSBigIntString = true
+	}
+}
+
+// WithFlavor sets the library the models are written for, FlavorGorm by default.
+func WithFlavor(flavor Flavor) Option {
+	return func(o *options) {
+		o.Flavor = flavor
+	}
+}
+
 // parseOption .
 func parseOption(options []Option) options {
 	o := defaultOptions
@@ -13,7 +13,6 @@ import (
 	"github.com/blastrain/vitess-sqlparser/tidbparser/ast"
 	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/mysql"
 	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/types"
-	"github.com/blastrain/vitess-sqlparser/tidbparser/parser"
 	"github.com/jinzhu/inflection"
 	"github.com/pkg/errors"
 )
@@ -41,25 +40,34 @@ type ModelCodes struct {
 
 // ParseSQL .
 func ParseSQL(sql string, options ...Option) (ModelCodes, error) {
-	initTemplate()
-	opt := parseOption(options)
+	tables, err := GetTablesFromSQL(sql, options...)
+	if err != nil {
+		return ModelCodes{}, err
+	}
+	return ParseTables(tables, options...)
+}
 
-	stmts, err := parser.New().Parse(sql, opt.Charset, opt.Collation)
+// ParseTables .
+func ParseTables(tables []*Table, options ...Option) (ModelCodes, error) {
+	opt := parseOption(options)
+	structTmpl, err := loadStructTemplate(opt)
 	if err != nil {
 		return ModelCodes{}, err
 	}
-	tableStr := make([]string, 0, len(stmts))
+	if opt.Schema == nil {
+		opt.Schema = tables
+	}
+
+	tableStr := make([]string, 0, len(tables))
 	importPath := make(map[string]struct{})
-	for _, stmt := range stmts {
-		if ct, ok := stmt.(*ast.CreateTableStmt); ok {
-			s, ipt, err := makeCode(ct, opt)
-			if err != nil {
-				return ModelCodes{}, err
-			}
-			tableStr = append(tableStr, s)
-			for _, s := range ipt {
-				importPath[s] = struct{}{}
-			}
+	for _, table := range tables {
+		s, ipt, err := makeCode(table, opt, structTmpl)
+		if err != nil {
+			return ModelCodes{}, err
+		}
+		tableStr = append(tableStr, s)
+		for _, s := range ipt {
+			importPath[s] = struct{}{}
 		}
 	}
 
@@ -82,11 +90,16 @@ func ParseSQLToWrite(sql string, writer io.Writer, options ...Option) error {
 	if err != nil {
 		return err
 	}
-	err = fileTmpl.Execute(writer, data)
+	return writeModelCodes(data, writer, options...)
+}
+
+// ParseTablesToWrite .
+func ParseTablesToWrite(tables []*Table, writer io.Writer, options ...Option) error {
+	data, err := ParseTables(tables, options...)
 	if err != nil {
 		return err
 	}
-	return nil
+	return writeModelCodes(data, writer, options...)
 }
 
 // ConfigureAcronym .
@@ -97,160 +110,248 @@ func ParseSQLToWrite(sql string, writer io.Writer, options ...Option) error {
 	}
 }*/
 
-// tmplData .
-type tmplData struct {
-	TableName    string      `json:"-"`
-	NameFunc     bool        `json:"-"`
+// TmplData is the data of the struct template, one per table.
+type TmplData struct {
+	TableName    string      `json:"-"` // go struct name
+	NameFunc     bool        `json:"-"` // whether TableName() should be written, gorm and xorm only
 	RawTableName string      `json:"-"`
-	Fields       []tmplField `json:"-"`
-	Comment      string      `json:"-"`
+	Fields       []TmplField `json:"-"`
+	Comment      string      `json:"-"` // struct comment, struct name followed by the table comment
+	TableComment string      `json:"-"` // raw table comment
+	Package      string      `json:"-"`
+	Indexes      []*Index    `json:"-"`
+	View         bool        `json:"-"` // the table is a view, the fields are read-only
+	// Associations are the BelongsTo/HasMany fields built from foreign keys, see WithAssociations
+	Associations []TmplField `json:"-"`
+	// Enums are the named types of the enum and set columns, declared after the struct
+	Enums []TmplEnum `json:"-"`
+	// Flavor is the library the struct is written for, sqlx also has the Columns/ScanFields helpers
+	Flavor string `json:"-"`
+	// Embed is the embedded field written first, bun.BaseModel with the table name for bun
+	Embed string `json:"-"`
 }
 
-// tmplField .
-type tmplField struct {
-	Name    string `json:"-"`
-	GoType  string `json:"-"`
-	Tag     string `json:"-"`
-	Comment string `json:"-"`
+// TmplField is a struct field with the metadata of its column.
+type TmplField struct {
+	Name          string   `json:"-"` // go field name
+	GoType        string   `json:"-"`
+	ImportPath    string   `json:"-"` // import path GoType needs, empty for builtin types
+	Tag           string   `json:"-"` // struct tag without backquotes
+	Comment       string   `json:"-"`
+	ColumnName    string   `json:"-"`
+	SQLType       string   `json:"-"` // e.g. varchar(64)
+	Nullable      bool     `json:"-"` // the column accepts NULL
+	Default       string   `json:"-"`
+	PrimaryKey    bool     `json:"-"`
+	AutoIncrement bool     `json:"-"`
+	Unique        bool     `json:"-"`
+	Indexes       []string `json:"-"` // names of the secondary indexes containing the column
 }
 
 // makeCode .
-func makeCode(stmt *ast.CreateTableStmt, opt options) (string, []string, error) {
+func makeCode(table *Table, opt options, structTmpl *template.Template) (string, []string, error) {
+	if opt.Flavor == FlavorEnt {
+		return makeEntCode(table, opt)
+	}
+	data, importPath := makeTmplData(table, opt)
+
+	builder := strings.Builder{}
+	err := structTmpl.Execute(&builder, data)
+	if err != nil {
+		return "", nil, err
+	}
+	code, err := format.Source([]byte(builder.String()))
+	if err != nil {
+		return string(code), importPath, errors.WithMessage(err, "format golang code error")
+	}
+	return string(code), importPath, nil
+}
+
+// makeTmplData builds the struct template data of table and the import paths its field types need.
+func makeTmplData(table *Table, opt options) (TmplData, []string) {
 	importPath := make([]string, 0, 1)
-	data := tmplData{
-		TableName:    stmt.Table.Name.String(),
-		RawTableName: stmt.Table.Name.String(),
-		Fields:       make([]tmplField, 0, 1),
+	data := TmplData{
+		TableName:    table.Name,
+		RawTableName: table.Name,
+		Fields:       make([]TmplField, 0, 1),
+		TableComment: table.Comment,
+		Package:      opt.Package,
+		Indexes:      table.Indexes,
+		View:         table.View,
+		Flavor:       string(opt.Flavor),
 	}
 	tablePrefix := opt.TablePrefix
 	if tablePrefix != "" && strings.HasPrefix(data.TableName, tablePrefix) {
 		data.NameFunc = true
-		tableNameTemp := data.TableName[len(tablePrefix):]
-		for _, v := range tableNameTemp {
-			if !unicode.IsNumber(v) {
-				data.TableName = data.TableName[len(tablePrefix):]
-			}
-			break
-		}
 	}
 	if opt.ForceTableName || data.RawTableName != inflection.Plural(data.RawTableName) {
 		data.NameFunc = true
 	}
-
-	data.TableName = toCamel(data.TableName)
-
-	// find table comment
-	for _, opt := range stmt.Options {
-		if opt.Tp == ast.TableOptionComment {
-			data.Comment = data.TableName + " " + opt.StrValue
-			break
-		}
+	// TableName() is read by gorm and xorm, bun embeds the table name and sqlx has none
+	if opt.Flavor == FlavorSQLx || opt.Flavor == FlavorBun {
+		data.NameFunc = false
 	}
 
-	if data.Comment == "" {
-		data.Comment = data.TableName + "  ."
-	}
+	data.TableName = structName(table.Name, opt)
 
-	isPrimaryKey := make(map[string]bool)
-	for _, con := range stmt.Constraints {
-		if con.Tp == ast.ConstraintPrimaryKey {
-			isPrimaryKey[con.Keys[0].Column.String()] = true
-		}
+	if table.Comment != "" {
+		data.Comment = data.TableName + " " + table.Comment
+	} else {
+		data.Comment = data.TableName + "  ."
 	}
 
-	columnPrefix := opt.ColumnPrefix
-	for _, col := range stmt.Cols {
-		colName := col.Name.Name.String()
-		goFieldName := colName
-
-		if columnPrefix != "" && strings.HasPrefix(goFieldName, columnPrefix) {
-			goFieldName = goFieldName[len(columnPrefix):]
-		}
-
-		name := strings.Replace(toCamel(goFieldName), "id", "ID", -1)
-		field := tmplField{
-			Name: strings.Replace(name, "Url", "URL", -1),
+	// the struct names of the schema, the enum types must not take them
+	var structs map[string]bool
+	for _, col := range table.Columns {
+		colName := col.Name
+		field := TmplField{
+			Name:          fieldName(colName, opt),
+			Comment:       col.Comment,
+			ColumnName:    colName,
+			SQLType:       col.SQLType,
+			Nullable:      !col.NotNull && !col.PrimaryKey,
+			Default:       col.Default,
+			PrimaryKey:    col.PrimaryKey,
+			AutoIncrement: col.AutoIncrement,
+			Unique:        col.Unique,
+			Indexes:       table.ColumnIndexes(colName),
 		}
 
-		tags := make([]string, 0, 4)
-		// make GORM's tag
-		gormTag := strings.Builder{}
-		gormTag.WriteString("column:")
-		gormTag.WriteString(colName)
-		if opt.GormType {
-			gormTag.WriteString(";type:")
-			gormTag.WriteString(col.Tp.InfoSchemaStr())
-		}
-		if isPrimaryKey[colName] {
-			gormTag.WriteString(";primary_key")
-		}
-		isNotNull := false
-		canNull := false
-
-		if opt.JSONTag {
-			tags = append(tags, "json", colName)
-		}
-
-		for _, o := range col.Options {
-			switch o.Tp {
-			case ast.ColumnOptionPrimaryKey:
-				if !isPrimaryKey[colName] {
-					gormTag.WriteString(";primary_key")
-					isPrimaryKey[colName] = true
-				}
-			case ast.ColumnOptionNotNull:
-				isNotNull = true
-			case ast.ColumnOptionAutoIncrement:
-				gormTag.WriteString(";AUTO_INCREMENT")
-			case ast.ColumnOptionDefaultValue:
-				if value := getDefaultValue(o.Expr); value != "" {
-					gormTag.WriteString(";default:")
-					gormTag.WriteString(value)
-				}
-			case ast.ColumnOptionUniqKey:
-				gormTag.WriteString(";unique")
-			case ast.ColumnOptionNull:
-				//gormTag.WriteString(";NULL")
-				canNull = true
-			case ast.ColumnOptionOnUpdate: // For Timestamp and Datetime only.
-			case ast.ColumnOptionFulltext:
-			case ast.ColumnOptionComment:
-				field.Comment = strings.Replace(o.Expr.GetDatum().GetString(), "\n", "", -1)
-			default:
-				//return "", nil, errors.Errorf(" unsupport option %d\n", o.Tp)
-			}
-		}
-		if !isPrimaryKey[colName] && isNotNull {
-			gormTag.WriteString(";NOT NULL")
-		}
-		tags = append(tags, "gorm", gormTag.String())
-
-		field.Tag = makeTagStr(tags)
-
 		// get type in golang
 		styleNull := opt.NullStyle
-		if !canNull {
+		if !col.Nullable {
 			styleNull = NullDisable
 		}
-		goType, pkg := mysqlToGoType(col.Tp, styleNull, opt.JudgeUnsigned)
+		goType, pkg, mapped := mappedGoType(opt.TypeMapping, table.Name, col, styleNull)
+		if !mapped {
+			goType, pkg = columnToGoType(table.Driver, col, styleNull, opt)
+			if structs == nil {
+				structs = schemaStructNames(table, opt)
+			}
+			if enum := makeEnum(data.TableName, field.Name, col, structs); enum != nil {
+				data.Enums = append(data.Enums, *enum)
+				goType, pkg = enum.goType(styleNull)
+				importPath = append(importPath, enum.importPath()...)
+			}
+		}
 		if pkg != "" {
 			importPath = append(importPath, pkg)
 		}
 		field.GoType = goType
+		field.ImportPath = pkg
+
+		tags := make([]string, 0, 4)
+		// the int64 of the TypeScript interfaces are strings, written with the ,string option
+		baseType := strings.TrimPrefix(goType, "*")
+		bigString := opt.TSBigIntString && (baseType == "int64" || baseType == "uint64")
+		switch {
+		case opt.JSONTag && bigString:
+			tags = append(tags, "json", colName+",string")
+		case opt.JSONTag:
+			tags = append(tags, "json", colName)
+		case bigString:
+			tags = append(tags, "json", ",string")
+		}
+		tags = append(tags, flavorTag(table, col, opt)...)
+
+		field.Tag = makeTagStr(tags)
 
 		data.Fields = append(data.Fields, field)
 	}
+	if opt.Flavor == FlavorBun {
+		data.Embed = "bun.BaseModel `" + makeTagStr([]string{"bun", "table:" + table.Name}) + "`"
+		importPath = append(importPath, "github.com/uptrace/bun")
+	}
+	// the associations are written with gorm tags
+	if opt.Associations && opt.Flavor == FlavorGorm {
+		data.Associations = makeAssociations(table, data.Fields, opt)
+	}
+	return data, importPath
+}
 
-	builder := strings.Builder{}
-	err := structTmpl.Execute(&builder, data)
-	if err != nil {
-		return "", nil, err
+// gormTag is the gorm tag of col, e.g. column:id;primaryKey;AUTO_INCREMENT.
+func gormTag(table *Table, col *Column, opt options) string {
+	gormTag := strings.Builder{}
+	gormTag.WriteString("column:")
+	gormTag.WriteString(col.Name)
+	if opt.GormType {
+		gormTag.WriteString(";type:")
+		gormTag.WriteString(col.SQLType)
 	}
-	code, err := format.Source([]byte(builder.String()))
-	if err != nil {
-		return string(code), importPath, errors.WithMessage(err, "format golang code error")
+	if table.View {
+		// read only, gorm never writes the field
+		gormTag.WriteString(";->")
+	}
+	if col.PrimaryKey {
+		gormTag.WriteString(";primaryKey")
+	}
+	if col.AutoIncrement {
+		gormTag.WriteString(";AUTO_INCREMENT")
+	}
+	if col.Default != "" {
+		gormTag.WriteString(";default:")
+		gormTag.WriteString(col.Default)
+	}
+	if col.Unique {
+		gormTag.WriteString(";unique")
+	}
+	if !col.PrimaryKey && col.NotNull {
+		gormTag.WriteString(";NOT NULL")
+	}
+	for _, index := range table.gormIndexTags(col.Name) {
+		gormTag.WriteString(";")
+		gormTag.WriteString(index)
+	}
+	return gormTag.String()
+}
+
+// schemaStructNames returns the struct names of table, the tables of the schema and the table names,
+// they are also the proto message names.
+func schemaStructNames(table *Table, opt options) map[string]bool {
+	names := map[string]bool{structName(table.Name, opt): true}
+	for _, t := range opt.Schema {
+		names[structName(t.Name, opt)] = true
+	}
+	for _, name := range opt.TableNames {
+		names[structName(name, opt)] = true
+	}
+	return names
+}
+
+// structName is the go struct name of table, the table prefix is trimmed unless the rest starts with a number.
+func structName(tableName string, opt options) string {
+	name := tableName
+	if opt.TablePrefix != "" && strings.HasPrefix(name, opt.TablePrefix) {
+		for _, v := range name[len(opt.TablePrefix):] {
+			if !unicode.IsNumber(v) {
+				name = name[len(opt.TablePrefix):]
+			}
+			break
+		}
+	}
+	return toCamel(name)
+}
+
+// fieldName is the go field name of column colName.
+func fieldName(colName string, opt options) string {
+	name := colName
+	if opt.ColumnPrefix != "" && strings.HasPrefix(name, opt.ColumnPrefix) {
+		name = name[len(opt.ColumnPrefix):]
+	}
+	name = strings.Replace(toCamel(name), "id", "ID", -1)
+	return strings.Replace(name, "Url", "URL", -1)
+}
+
+// columnToGoType .
+func columnToGoType(driver Driver, col *Column, style NullStyle, opt options) (name string, path string) {
+	switch driver {
+	case DriverPostgres:
+		return postgresToGoType(col, style)
+	case DriverSQLite:
+		return sqliteToGoType(col, style)
+	default:
+		return mysqlToGoType(col.Tp, style, opt.JudgeUnsigned)
 	}
-	return string(code), importPath, nil
 }
 
 // mysqlToGoType .
@@ -268,12 +369,15 @@ func mysqlToGoType(colTp *types.FieldType, style NullStyle, judgeUnsigned bool)
 		case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString,
 			mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
 			name = "sql.NullString"
-		case mysql.TypeTimestamp, mysql.TypeDatetime, mysql.TypeDate:
+		case mysql.TypeTimestamp, mysql.TypeDatetime, mysql.TypeDate, mysql.TypeNewDate:
 			name = "sql.NullTime"
-		case mysql.TypeJSON:
+		case mysql.TypeJSON, mysql.TypeDuration, mysql.TypeEnum, mysql.TypeSet:
 			name = "sql.NullString"
+		case mysql.TypeYear:
+			name = "sql.NullInt32"
 		default:
-			return "UnSupport", ""
+			// bit, geometry and the others are read as raw bytes, nil for NULL
+			return "[]byte", ""
 		}
 	} else {
 		switch colTp.Tp {
@@ -297,13 +401,16 @@ func mysqlToGoType(colTp *types.FieldType, style NullStyle, judgeUnsigned bool)
 		case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString,
 			mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob:
 			name = "string"
-		case mysql.TypeTimestamp, mysql.TypeDatetime, mysql.TypeDate:
+		case mysql.TypeTimestamp, mysql.TypeDatetime, mysql.TypeDate, mysql.TypeNewDate:
 			path = "time"
 			name = "time.Time"
-		case mysql.TypeJSON:
+		case mysql.TypeJSON, mysql.TypeDuration, mysql.TypeEnum, mysql.TypeSet:
 			name = "string"
+		case mysql.TypeYear:
+			name = "int"
 		default:
-			return "UnSupport", ""
+			// bit, geometry and the others are read as raw bytes
+			return "[]byte", ""
 		}
 
 		if style == NullInPointer {
@@ -386,11 +493,11 @@ func toCamel(s string) string {
 func initTemplate() {
 	tmplParseOnce.Do(func() {
 		var err error
-		structTmpl, err = template.New("goStruct").Parse(structTmplRaw)
+		structTmpl, err = template.New("goStruct").Funcs(tmplFuncs).Parse(structTmplRaw)
 		if err != nil {
 			panic(err)
 		}
-		fileTmpl, err = template.New("goFile").Parse(fileTmplRaw)
+		fileTmpl, err = template.New("goFile").Funcs(tmplFuncs).Parse(fileTmplRaw)
 		if err != nil {
 			panic(err)
 		}
@@ -402,17 +509,101 @@ func init() {
 {{- if .Comment -}}
 // {{.Comment}}
 {{end -}}
+{{- if .View -}}
+// {{.TableName}} is read-only, it maps the view {{.RawTableName}}.
+{{end -}}
 type {{.TableName}} struct {
+{{- if .Embed}}
+	{{.Embed}}
+{{end}}
 {{- range .Fields}}
 	{{.Name}} {{.GoType}} {{if .Tag}}` + "`{{.Tag}}`" + `{{end}}{{if .Comment}} // {{.Comment}}{{end}}
 {{- end}}
+{{- if .Associations}}
+{{range .Associations}}
+	{{.Name}} {{.GoType}} {{if .Tag}}` + "`{{.Tag}}`" + `{{end}}{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}
+{{- end}}
 }
 {{if .NameFunc}}
 // TableName .
 func (m *{{.TableName}}) TableName() string {
 	return "{{.RawTableName}}"
 }
-{{end}}`
+{{end}}
+{{- if eq .Flavor "sqlx"}}
+// Columns returns the columns of {{.RawTableName}} in the order of the fields.
+func (m *{{.TableName}}) Columns() []string {
+	return []string{ {{- range $i, $f := .Fields}}{{if $i}}, {{end}}{{printf "%q" $f.ColumnName}}{{end -}} }
+}
+
+// ScanFields returns pointers to the fields in the order of Columns, e.g. rows.Scan(m.ScanFields()...).
+func (m *{{.TableName}}) ScanFields() []interface{} {
+	return []interface{}{ {{- range $i, $f := .Fields}}{{if $i}}, {{end}}&m.{{$f.Name}}{{end -}} }
+}
+{{end}}
+{{- range $e := .Enums}}
+{{if .Set}}
+// {{.Name}} is the value of set column {{.Column}}.
+type {{.Name}} []string
+
+// {{.Name}} elements.
+const (
+{{- range .Values}}
+	{{.Name}} = {{printf "%q" .Value}}
+{{- end}}
+)
+
+// Scan implements sql.Scanner.
+func (s *{{.Name}}) Scan(src interface{}) error {
+	var str string
+	switch v := src.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return fmt.Errorf("scan %T into {{.Name}}", src)
+	}
+	if str == "" {
+		*s = {{.Name}}{}
+		return nil
+	}
+	*s = strings.Split(str, ",")
+	return nil
+}
+
+// Value implements driver.Valuer, a nil set is NULL and an empty one ''.
+func (s {{.Name}}) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return strings.Join(s, ","), nil
+}
+{{else}}
+// {{.Name}} is the value of enum column {{.Column}}.
+type {{.Name}} string
+
+// {{.Name}} values.
+const (
+{{- range .Values}}
+	{{.Name}} {{$e.Name}} = {{printf "%q" .Value}}
+{{- end}}
+)
+
+// IsValid reports whether e is one of the {{.Name}} values.
+func (e {{.Name}}) IsValid() bool {
+	switch e {
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v.Name}}{{end}}:
+		return true
+	}
+	return false
+}
+{{end}}
+{{- end}}`
 	fileTmplRaw = `package {{.Package}}
 {{if .ImportPath}}
 import (
@@ -0,0 +1,332 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testDDL = "CREATE TABLE `tbl_user_info` (\n" +
+	"  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `user_id` int(11) NOT NULL DEFAULT '0' COMMENT 'user id',\n" +
+	"  `name` varchar(64) NOT NULL DEFAULT '' COMMENT 'name',\n" +
+	"  `score` decimal(10,2) NOT NULL DEFAULT '0.00',\n" +
+	"  `deleted_at` timestamp NULL DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='users';"
+
+// containsField reports whether code declares a struct field name with goType.
+func containsField(code, name, goType string) bool {
+	re := regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(strings.TrimSpace(name)) + `\s+` + regexp.QuoteMeta(goType) + `\s`)
+	return re.MatchString(code)
+}
+
+func TestParseSQL(t *testing.T) {
+	codes, err := ParseSQL(testDDL, WithTablePrefix("tbl_"), WithJSONTag(), WithGormType(), WithPackage("internal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes.Package != "internal" || len(codes.StructCode) != 1 {
+		t.Fatalf("unexpected codes: %+v", codes)
+	}
+	code := codes.StructCode[0]
+	for _, s := range []string{
+		"// UserInfo users",
+		"type UserInfo struct",
+		"`json:\"id\" gorm:\"column:id;type:bigint(20) unsigned;primaryKey;AUTO_INCREMENT\"`",
+		"`json:\"user_id\" gorm:\"column:user_id;type:int(11);default:0;NOT NULL\"` // user id",
+		"return \"tbl_user_info\"",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("%q not found in:\n%s", s, code)
+		}
+	}
+	if !containsField(code, "DeletedAt", "sql.NullTime") {
+		t.Errorf("DeletedAt should be sql.NullTime:\n%s", code)
+	}
+}
+
+// testDump is the verbatim output of mysqldump --no-data, with the bare -- comment lines.
+const testDump = `-- MySQL dump 10.13  Distrib 8.0.32, for Linux (x86_64)
+--
+-- Host: localhost    Database: shop
+-- ------------------------------------------------------
+-- Server version	8.0.32
+
+/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;
+/*!40101 SET @OLD_CHARACTER_SET_RESULTS=@@CHARACTER_SET_RESULTS */;
+/*!40101 SET @OLD_COLLATION_CONNECTION=@@COLLATION_CONNECTION */;
+/*!50503 SET NAMES utf8mb4 */;
+/*!40103 SET @OLD_TIME_ZONE=@@TIME_ZONE */;
+/*!40103 SET TIME_ZONE='+00:00' */;
+/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;
+/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;
+/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;
+/*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;
+
+--
+-- Table structure for table ` + "`users`" + `
+--
+
+DROP TABLE IF EXISTS ` + "`users`" + `;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE ` + "`users`" + ` (
+  ` + "`id`" + ` int NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (` + "`id`" + `)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table ` + "`orders`" + `
+--
+
+DROP TABLE IF EXISTS ` + "`orders`" + `;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE ` + "`orders`" + ` (
+  ` + "`id`" + ` int NOT NULL,
+  ` + "`user_id`" + ` int NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;
+
+ALTER TABLE ` + "`orders`" + ` ADD PRIMARY KEY (` + "`id`" + `);
+
+/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;
+/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;
+/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;
+/*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;
+/*!40101 SET CHARACTER_SET_RESULTS=@OLD_CHARACTER_SET_RESULTS */;
+/*!40101 SET COLLATION_CONNECTION=@OLD_COLLATION_CONNECTION */;
+/*!40111 SET SQL_NOTES=@OLD_SQL_NOTES */;
+
+-- Dump completed on 2023-03-01 10:00:00
+`
+
+func TestGetTablesFromSQL(t *testing.T) {
+	tables, err := GetTablesFromSQL(testDump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || tables[0].Name != "users" || tables[1].Name != "orders" {
+		t.Fatalf("unexpected tables: %+v", tables)
+	}
+	if len(tables[1].Columns) != 2 {
+		t.Errorf("orders should have 2 columns, got %d", len(tables[1].Columns))
+	}
+}
+
+func TestParseSQLIndexes(t *testing.T) {
+	codes, err := ParseSQL("CREATE TABLE `user_roles` (\n" +
+		"  `user_id` int NOT NULL,\n" +
+		"  `role_id` int NOT NULL,\n" +
+		"  `tenant_id` int NOT NULL,\n" +
+		"  `note` text NOT NULL,\n" +
+		"  PRIMARY KEY (`user_id`, `role_id`),\n" +
+		"  UNIQUE KEY `uk_tenant_role` (`tenant_id`, `role_id`),\n" +
+		"  KEY (`tenant_id`),\n" +
+		"  FULLTEXT KEY `ft_note` (`note`)\n" +
+		");")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := codes.StructCode[0]
+	for _, s := range []string{
+		`gorm:"column:user_id;primaryKey"`,
+		`gorm:"column:role_id;primaryKey;uniqueIndex:uk_tenant_role,priority:2"`,
+		`gorm:"column:tenant_id;NOT NULL;uniqueIndex:uk_tenant_role,priority:1;index:tenant_id"`,
+		`gorm:"column:note;NOT NULL;index:ft_note,class:FULLTEXT"`,
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("%s not found in:\n%s", s, code)
+		}
+	}
+}
+
+func TestParseSQLAssociations(t *testing.T) {
+	sql := "CREATE TABLE `users` (`id` int NOT NULL, PRIMARY KEY (`id`));\n" +
+		"CREATE TABLE `orders` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `user_id` int NOT NULL,\n" +
+		"  `created_by` int NOT NULL REFERENCES `users` (`id`),\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE\n" +
+		");"
+	codes, err := ParseSQL(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(codes.StructCode[1], "*Users") {
+		t.Errorf("associations should be opt-in:\n%s", codes.StructCode[1])
+	}
+
+	codes, err = ParseSQL(sql, WithHasMany(), WithJSONTag())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, fields := range []map[string]string{
+		{
+			"CreatedByOrders": "[]Orders `json:\"created_by_orders,omitempty\" gorm:\"foreignKey:CreatedBy;references:ID\"`",
+			"UserOrders":      "[]Orders `json:\"user_orders,omitempty\" gorm:\"foreignKey:UserID;references:ID\"`",
+		},
+		{
+			"CreatedByUser": "*Users `json:\"created_by_user,omitempty\" gorm:\"foreignKey:CreatedBy;references:ID\"`",
+			"User":          "*Users `json:\"user,omitempty\" gorm:\"foreignKey:UserID;references:ID\"`",
+		},
+	} {
+		for field, goType := range fields {
+			if !containsField(codes.StructCode[i], field, goType) {
+				t.Errorf("field %s %s not found in:\n%s", field, goType, codes.StructCode[i])
+			}
+		}
+	}
+
+	// the parent is resolved against the schema when a single table is parsed
+	tables, err := GetTablesFromSQL(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err = ParseTables(tables[1:], WithAssociations(), WithSchema(tables))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsField(codes.StructCode[0], "User", "*Users") {
+		t.Errorf("field User *Users not found in:\n%s", codes.StructCode[0])
+	}
+}
+
+func TestParseSQLEnum(t *testing.T) {
+	codes, err := ParseSQL("CREATE TABLE `orders` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `status` enum('paid','un-paid','') NOT NULL,\n" +
+		"  `tags` set('a','b c') NOT NULL,\n" +
+		"  `flag` bit(1) NOT NULL,\n" +
+		"  `year` year NOT NULL,\n" +
+		"  `duration` time NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := codes.StructCode[0]
+	for field, goType := range map[string]string{
+		"Status":   "OrderStatus",
+		"Tags":     "OrderTags",
+		"Flag":     "[]byte",
+		"Year":     "int",
+		"Duration": "sql.NullString",
+	} {
+		if !containsField(code, field, goType) {
+			t.Errorf("field %s %s not found in:\n%s", field, goType, code)
+		}
+	}
+	for _, s := range []string{
+		"type OrderStatus string",
+		`OrderStatusUnPaid OrderStatus = "un-paid"`,
+		`OrderStatusEmpty  OrderStatus = ""`,
+		"func (e OrderStatus) IsValid() bool",
+		"type OrderTags []string",
+		`OrderTagsBC = "b c"`,
+		"func (s *OrderTags) Scan(src interface{}) error",
+		"func (s OrderTags) Value() (driver.Value, error) {\n\tif s == nil {\n\t\treturn nil, nil\n\t}",
+		"\tcase nil:\n\t\t*s = nil\n\t\treturn nil\n",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("%q not found in:\n%s", s, code)
+		}
+	}
+	if strings.Contains(code, "UnSupport") {
+		t.Errorf("unsupported type in:\n%s", code)
+	}
+	if strings.Join(codes.ImportPath, ",") != "database/sql,database/sql/driver,fmt,strings" {
+		t.Errorf("unexpected import path: %v", codes.ImportPath)
+	}
+}
+
+func TestParseSQLEnumStructName(t *testing.T) {
+	tables, err := GetTablesFromSQL("CREATE TABLE `users` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `roles` set('admin','editor') NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");\n" +
+		"CREATE TABLE `user_roles` (\n" +
+		"  `user_id` int NOT NULL,\n" +
+		"  `role` varchar(32) NOT NULL,\n" +
+		"  PRIMARY KEY (`user_id`, `role`)\n" +
+		");")
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := ParseTables(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := strings.Join(codes.StructCode, "\n")
+	if !containsField(code, "Roles", "UserRolesType") || !strings.Contains(code, "type UserRolesType []string") {
+		t.Errorf("the set type should not take the struct name UserRoles:\n%s", code)
+	}
+	if strings.Count(code, "type UserRoles ") != 1 {
+		t.Errorf("UserRoles should be declared once:\n%s", code)
+	}
+
+	// one table per file, the names are checked against the schema
+	proto, err := MakeProto(tables[0], nil, WithSchema(tables))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(proto, "repeated UserRolesType roles = 2;") || !strings.Contains(proto, "enum UserRolesType {") {
+		t.Errorf("the proto enum should not take the message name UserRoles:\n%s", proto)
+	}
+
+	// users selected alone is checked against all table names, so is its model by diff
+	codes, err = ParseTables(tables[:1], WithTableNames("users", "user_roles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsField(codes.StructCode[0], "Roles", "UserRolesType") {
+		t.Errorf("the set type should not take the struct name UserRoles:\n%s", codes.StructCode[0])
+	}
+	models := writeModels(t, "CREATE TABLE `users` (`id` int NOT NULL, `roles` set('admin','editor') NOT NULL, PRIMARY KEY (`id`));",
+		WithTableNames("users", "user_roles"))
+	if diff := DiffModel(models[0], tables[0], WithTableNames("users", "user_roles")); len(diff) != 0 {
+		t.Errorf("unexpected drift of the suffixed set type: %v", diff)
+	}
+}
+
+func TestWithTypeMapping(t *testing.T) {
+	codes, err := ParseSQL("CREATE TABLE `orders` (\n"+
+		"  `id` int NOT NULL,\n"+
+		"  `amount` decimal(10,2) NOT NULL,\n"+
+		"  `active` tinyint(1) NOT NULL,\n"+
+		"  `level` tinyint(4) NOT NULL,\n"+
+		"  `meta` json NULL,\n"+
+		"  `extra` json NULL,\n"+
+		"  PRIMARY KEY (`id`)\n"+
+		");",
+		WithNullStyle(NullInPointer),
+		WithTypeMapping(
+			TypeMapping{SQLType: "decimal", GoType: "int64"},
+			TypeMapping{SQLType: "tinyint(1)", GoType: "bool"},
+			TypeMapping{SQLType: "json", GoType: "datatypes.JSON", Import: "gorm.io/datatypes"},
+			TypeMapping{Column: "orders.meta", GoType: "OrderMeta"},
+		))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := codes.StructCode[0]
+	for field, goType := range map[string]string{
+		"Amount": "int64",
+		"Active": "bool",
+		"Level":  "int",
+		"Meta":   "*OrderMeta",
+		"Extra":  "*datatypes.JSON",
+	} {
+		if !containsField(code, field, goType) {
+			t.Errorf("field %s %s not found in:\n%s", field, goType, code)
+		}
+	}
+	if strings.Join(codes.ImportPath, ",") != "gorm.io/datatypes" {
+		t.Errorf("unexpected import path: %v", codes.ImportPath)
+	}
+}
@@ -0,0 +1,380 @@
+package parser
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/mysql"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/types"
+	_ "github.com/lib/pq" // "postgres"
+	"github.com/pkg/errors"
+)
+
+const pgColumnsSQL = `SELECT c.column_name, c.udt_name, format_type(a.atttypid, a.atttypmod),
+	c.is_nullable = 'YES', COALESCE(c.column_default, ''), c.is_identity = 'YES',
+	COALESCE(c.character_maximum_length, 0), COALESCE(c.numeric_precision, 0), COALESCE(c.numeric_scale, 0),
+	COALESCE(col_description(a.attrelid, a.attnum), '')
+FROM information_schema.columns c
+JOIN pg_catalog.pg_attribute a
+	ON a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass AND a.attname = c.column_name
+WHERE c.table_schema = $1 AND c.table_name = $2
+ORDER BY c.ordinal_position`
+
+const pgPrimaryKeySQL = `SELECT a.attname
+FROM pg_catalog.pg_index i
+JOIN pg_catalog.pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+WHERE i.indrelid = format('%I.%I', $1::text, $2::text)::regclass AND i.indisprimary`
+
+const pgIndexesSQL = `SELECT ic.relname, i.indisunique, a.attname
+FROM pg_catalog.pg_index i
+JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, ord)
+JOIN pg_catalog.pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+WHERE i.indrelid = format('%I.%I', $1::text, $2::text)::regclass
+	AND NOT i.indisprimary AND i.indexprs IS NULL AND i.indpred IS NULL
+ORDER BY ic.relname, k.ord`
+
+const pgForeignKeysSQL = `SELECT con.conname, a.attname, rt.relname, ra.attname
+FROM pg_catalog.pg_constraint con
+CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(col, ref, ord)
+JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.col
+JOIN pg_catalog.pg_class rt ON rt.oid = con.confrelid
+JOIN pg_catalog.pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.ref
+WHERE con.conrelid = format('%I.%I', $1::text, $2::text)::regclass AND con.contype = 'f'
+ORDER BY con.conname, k.ord`
+
+const pgTableCommentSQL = `SELECT COALESCE(obj_description(format('%I.%I', $1::text, $2::text)::regclass, 'pg_class'), '')`
+
+const pgTablesSQL = `SELECT table_name FROM information_schema.tables
+WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+ORDER BY table_name`
+
+const pgViewsSQL = `SELECT table_name FROM information_schema.views
+WHERE table_schema = current_schema()
+ORDER BY table_name`
+
+const pgIsViewSQL = `SELECT EXISTS (SELECT 1 FROM information_schema.views WHERE table_schema = $1 AND table_name = $2)`
+
+// pgCastRe strips the type cast of a default value, e.g. 'abc'::character varying
+var pgCastRe = regexp.MustCompile(`::[a-zA-Z_ ]+(\[\])?$`)
+
+// getPostgresTables .
+func getPostgresTables(dsn string) ([]string, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, errors.WithMessage(err, "open db error")
+	}
+	defer db.Close()
+	return postgresTables(context.Background(), db)
+}
+
+// postgresTables .
+func postgresTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, pgTablesSQL)
+	if err != nil {
+		return nil, errors.WithMessage(err, "query tables error")
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// postgresViews .
+func postgresViews(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, pgViewsSQL)
+	if err != nil {
+		return nil, errors.WithMessage(err, "query views error")
+	}
+	defer rows.Close()
+	var views []string
+	for rows.Next() {
+		var view string
+		if err = rows.Scan(&view); err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	return views, rows.Err()
+}
+
+// GetTableFromPostgres reads tableName from information_schema/pg_catalog,
+// tableName may be qualified by schema, otherwise current_schema() is used.
+func GetTableFromPostgres(dsn, tableName string) (*Table, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, errors.WithMessage(err, "open db error")
+	}
+	defer db.Close()
+	return postgresTable(context.Background(), db, tableName)
+}
+
+// postgresTable .
+func postgresTable(ctx context.Context, db *sql.DB, tableName string) (*Table, error) {
+	schema, name := "", tableName
+	if i := strings.Index(tableName, "."); i >= 0 {
+		schema, name = tableName[:i], tableName[i+1:]
+	} else if err := db.QueryRowContext(ctx, "SELECT current_schema()").Scan(&schema); err != nil {
+		return nil, errors.WithMessage(err, "query current schema error")
+	}
+
+	table := &Table{Name: name, Driver: DriverPostgres}
+	rows, err := db.QueryContext(ctx, pgColumnsSQL, schema, name)
+	if err != nil {
+		return nil, errors.WithMessage(err, "query columns error")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			col                      Column
+			nullable, identity       bool
+			defaultValue             string
+			length, precision, scale int
+		)
+		err = rows.Scan(&col.Name, &col.DBType, &col.SQLType, &nullable, &defaultValue, &identity,
+			&length, &precision, &scale, &col.Comment)
+		if err != nil {
+			return nil, err
+		}
+		col.Nullable = nullable
+		col.NotNull = !nullable
+		col.AutoIncrement = identity || strings.HasPrefix(defaultValue, "nextval(")
+		if !col.AutoIncrement {
+			col.Default = pgDefaultValue(defaultValue)
+		}
+		col.Tp = pgFieldType(col.DBType, length, precision, scale)
+		table.Columns = append(table.Columns, &col)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(table.Columns) == 0 {
+		return nil, errors.Errorf("table(%s) not found", tableName)
+	}
+
+	pkRows, err := db.QueryContext(ctx, pgPrimaryKeySQL, schema, name)
+	if err != nil {
+		return nil, errors.WithMessage(err, "query primary key error")
+	}
+	defer pkRows.Close()
+	for pkRows.Next() {
+		var colName string
+		if err = pkRows.Scan(&colName); err != nil {
+			return nil, err
+		}
+		if col := findColumn(table, colName); col != nil {
+			col.PrimaryKey = true
+		}
+	}
+	if err = pkRows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = getPostgresIndexes(ctx, db, table, schema, name); err != nil {
+		return nil, err
+	}
+	if err = getPostgresForeignKeys(ctx, db, table, schema, name); err != nil {
+		return nil, err
+	}
+
+	if err = db.QueryRowContext(ctx, pgTableCommentSQL, schema, name).Scan(&table.Comment); err != nil {
+		return nil, errors.WithMessage(err, "query table comment error")
+	}
+	// the columns of a view are in information_schema.columns too, it just has no keys
+	if err = db.QueryRowContext(ctx, pgIsViewSQL, schema, name).Scan(&table.View); err != nil {
+		return nil, errors.WithMessage(err, "query views error")
+	}
+	return table, nil
+}
+
+// getPostgresIndexes reads the secondary indexes, expression and partial indexes are skipped.
+func getPostgresIndexes(ctx context.Context, db *sql.DB, table *Table, schema, name string) error {
+	rows, err := db.QueryContext(ctx, pgIndexesSQL, schema, name)
+	if err != nil {
+		return errors.WithMessage(err, "query indexes error")
+	}
+	defer rows.Close()
+	var index *Index
+	for rows.Next() {
+		var (
+			indexName, colName string
+			unique             bool
+		)
+		if err = rows.Scan(&indexName, &unique, &colName); err != nil {
+			return err
+		}
+		if index == nil || index.Name != indexName {
+			index = &Index{Name: indexName, Unique: unique}
+			table.Indexes = append(table.Indexes, index)
+		}
+		index.Columns = append(index.Columns, colName)
+	}
+	return rows.Err()
+}
+
+// getPostgresForeignKeys .
+func getPostgresForeignKeys(ctx context.Context, db *sql.DB, table *Table, schema, name string) error {
+	rows, err := db.QueryContext(ctx, pgForeignKeysSQL, schema, name)
+	if err != nil {
+		return errors.WithMessage(err, "query foreign keys error")
+	}
+	defer rows.Close()
+	var fk *ForeignKey
+	for rows.Next() {
+		var fkName, colName, refTable, refColumn string
+		if err = rows.Scan(&fkName, &colName, &refTable, &refColumn); err != nil {
+			return err
+		}
+		if fk == nil || fk.Name != fkName {
+			fk = &ForeignKey{Name: fkName, RefTable: refTable}
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+		fk.Columns = append(fk.Columns, colName)
+		fk.RefColumns = append(fk.RefColumns, refColumn)
+	}
+	return rows.Err()
+}
+
+// findColumn .
+func findColumn(table *Table, name string) *Column {
+	for _, col := range table.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// pgDefaultValue .
+func pgDefaultValue(value string) string {
+	value = pgCastRe.ReplaceAllString(value, "")
+	if value == "NULL" {
+		return ""
+	}
+	return unquoteDefault(value)
+}
+
+// pgFieldType maps postgres udt name to the closest mysql field type.
+func pgFieldType(udt string, length, precision, scale int) *types.FieldType {
+	var ft *types.FieldType
+	switch strings.TrimPrefix(udt, "_") {
+	case "int2":
+		ft = types.NewFieldType(mysql.TypeShort)
+	case "int4":
+		ft = types.NewFieldType(mysql.TypeLong)
+	case "int8":
+		ft = types.NewFieldType(mysql.TypeLonglong)
+	case "bool":
+		ft = types.NewFieldType(mysql.TypeTiny)
+		ft.Flen = 1
+	case "float4":
+		ft = types.NewFieldType(mysql.TypeFloat)
+	case "float8":
+		ft = types.NewFieldType(mysql.TypeDouble)
+	case "numeric", "money":
+		ft = types.NewFieldType(mysql.TypeNewDecimal)
+		ft.Flen, ft.Decimal = precision, scale
+	case "varchar":
+		ft = types.NewFieldType(mysql.TypeVarchar)
+		ft.Flen = length
+	case "bpchar":
+		ft = types.NewFieldType(mysql.TypeString)
+		ft.Flen = length
+	case "uuid":
+		ft = types.NewFieldType(mysql.TypeString)
+		ft.Flen = 36
+	case "json", "jsonb":
+		ft = types.NewFieldType(mysql.TypeJSON)
+	case "bytea":
+		ft = types.NewFieldType(mysql.TypeBlob)
+		ft.Flag |= mysql.BinaryFlag
+	case "timestamp":
+		ft = types.NewFieldType(mysql.TypeDatetime)
+	case "timestamptz":
+		ft = types.NewFieldType(mysql.TypeTimestamp)
+	case "date":
+		ft = types.NewFieldType(mysql.TypeDate)
+	case "time", "timetz", "interval":
+		ft = types.NewFieldType(mysql.TypeDuration)
+	default:
+		ft = types.NewFieldType(mysql.TypeBlob)
+	}
+	return ft
+}
+
+// postgresToGoType .
+func postgresToGoType(col *Column, style NullStyle) (name string, path string) {
+	if strings.HasPrefix(col.DBType, "_") {
+		// arrays are nullable by themselves
+		path = "github.com/lib/pq"
+		switch col.DBType[1:] {
+		case "int2", "int4", "int8":
+			name = "pq.Int64Array"
+		case "float4", "float8", "numeric":
+			name = "pq.Float64Array"
+		case "bool":
+			name = "pq.BoolArray"
+		case "bytea":
+			name = "pq.ByteaArray"
+		default:
+			name = "pq.StringArray"
+		}
+		return
+	}
+
+	if style == NullInSQL {
+		path = "database/sql"
+		switch col.DBType {
+		case "int2", "int4":
+			name = "sql.NullInt32"
+		case "int8":
+			name = "sql.NullInt64"
+		case "bool":
+			name = "sql.NullBool"
+		case "float4", "float8", "numeric":
+			name = "sql.NullFloat64"
+		case "timestamp", "timestamptz", "date":
+			name = "sql.NullTime"
+		case "bytea":
+			return "[]byte", ""
+		default:
+			name = "sql.NullString"
+		}
+		return
+	}
+
+	switch col.DBType {
+	case "int2", "int4":
+		name = "int"
+	case "int8":
+		name = "int64"
+	case "bool":
+		name = "bool"
+	case "float4", "float8":
+		name = "float64"
+	case "numeric":
+		path = "github.com/shopspring/decimal"
+		name = "decimal.Decimal"
+	case "timestamp", "timestamptz", "date":
+		path = "time"
+		name = "time.Time"
+	case "bytea":
+		return "[]byte", ""
+	default:
+		// text, varchar, uuid, json, jsonb, inet, interval ...
+		name = "string"
+	}
+
+	if style == NullInPointer {
+		name = "*" + name
+	}
+	return
+}
@@ -0,0 +1,398 @@
+package parser
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+var (
+	protoTmplRaw   string
+	protoTmpl      *template.Template
+	protoParseOnce sync.Once
+)
+
+// protoTypes are the proto3 scalar types of the go field types, columns are mapped with NullDisable.
+var protoTypes = map[string]string{
+	"int":             "int32",
+	"int32":           "int32",
+	"int64":           "int64",
+	"uint":            "uint32",
+	"uint32":          "uint32",
+	"uint64":          "uint64",
+	"bool":            "bool",
+	"float32":         "float",
+	"float64":         "double",
+	"string":          "string",
+	"[]byte":          "bytes",
+	"decimal.Decimal": "string", // keeps the precision
+	"time.Time":       "google.protobuf.Timestamp",
+}
+
+// protoArrayTypes are the element types of the postgres arrays, written as repeated fields.
+var protoArrayTypes = map[string]string{
+	"pq.Int64Array":   "int64",
+	"pq.Float64Array": "double",
+	"pq.BoolArray":    "bool",
+	"pq.ByteaArray":   "bytes",
+	"pq.StringArray":  "string",
+}
+
+// protoWrappers are the wrapper types of nullable scalar columns.
+var protoWrappers = map[string]string{
+	"int32":  "google.protobuf.Int32Value",
+	"int64":  "google.protobuf.Int64Value",
+	"uint32": "google.protobuf.UInt32Value",
+	"uint64": "google.protobuf.UInt64Value",
+	"bool":   "google.protobuf.BoolValue",
+	"float":  "google.protobuf.FloatValue",
+	"double": "google.protobuf.DoubleValue",
+	"string": "google.protobuf.StringValue",
+	"bytes":  "google.protobuf.BytesValue",
+}
+
+// protoWrapperScalars are the scalar types of the wrappers.
+var protoWrapperScalars = func() map[string]string {
+	scalars := make(map[string]string, len(protoWrappers))
+	for scalar, wrapper := range protoWrappers {
+		scalars[wrapper] = scalar
+	}
+	return scalars
+}()
+
+var (
+	protoMessageRe  = regexp.MustCompile(`^message\s+\w+\s*\{`)
+	protoFieldRe    = regexp.MustCompile(`^\s*(?:optional\s+)?((?:repeated\s+)?[\w.]+)\s+(\w+)\s*=\s*(\d+)\s*(?:\[[^\]]*\])?\s*;`)
+	protoReservedRe = regexp.MustCompile(`^\s*reserved\s+([^;]+);`)
+	protoNameRe     = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+// protoData .
+type protoData struct {
+	Package   string       `json:"-"`
+	GoPackage string       `json:"-"`
+	Imports   []string     `json:"-"`
+	Message   string       `json:"-"`
+	Comment   string       `json:"-"`
+	View      bool         `json:"-"`
+	Fields    []protoField `json:"-"`
+	// Reserved are the numbers and names of the fields dropped since the previous file
+	Reserved      string      `json:"-"`
+	ReservedNames string      `json:"-"`
+	Enums         []protoEnum `json:"-"`
+}
+
+// protoField .
+type protoField struct {
+	Type     string `json:"-"`
+	Name     string `json:"-"`
+	Number   int    `json:"-"`
+	Repeated bool   `json:"-"`
+	Enum     bool   `json:"-"`
+	Comment  string `json:"-"`
+}
+
+// fullType is the type written before the field name, e.g. repeated string.
+func (f protoField) fullType() string {
+	if f.Repeated {
+		return "repeated " + f.Type
+	}
+	return f.Type
+}
+
+// protoEnum is a mysql enum or set column, the number of a value is its mysql index (1 based),
+// 0 is the unspecified value which also stands for NULL.
+type protoEnum struct {
+	Name   string           `json:"-"`
+	Column string           `json:"-"`
+	Set    bool             `json:"-"` // the field is repeated
+	Values []protoEnumValue `json:"-"`
+}
+
+// protoEnumValue .
+type protoEnumValue struct {
+	Name   string `json:"-"`
+	Number int    `json:"-"`
+}
+
+// protoNumbers are the field numbers read back from the previous proto file.
+type protoNumbers struct {
+	Fields        map[string]int    `json:"-"`
+	Types         map[string]string `json:"-"` // field types, e.g. repeated string
+	Order         []string          `json:"-"` // field names in the order of the file
+	Reserved      map[int]bool      `json:"-"`
+	ReservedNames map[string]bool   `json:"-"`
+}
+
+// MakeProto renders the proto3 file of table, one message with the enums of its enum and set columns.
+// previous is the file generated by the last run, the field numbers in it are kept and
+// the numbers of the dropped columns are reserved, so the messages stay wire compatible.
+func MakeProto(table *Table, previous []byte, options ...Option) (string, error) {
+	opt := parseOption(options)
+	protoParseOnce.Do(func() {
+		protoTmpl = template.Must(template.New("goProto").Parse(protoTmplRaw))
+	})
+
+	model, fields := makeProtoFields(table, opt)
+	data := protoData{
+		Package:   opt.Package,
+		GoPackage: opt.ProtoGoPackage,
+		Message:   model.TableName,
+		Comment:   model.Comment,
+		View:      table.View,
+		Fields:    fields,
+	}
+	for _, enum := range model.Enums {
+		data.Enums = append(data.Enums, makeProtoEnum(enum))
+	}
+
+	imports := make(map[string]bool)
+	for _, field := range fields {
+		switch {
+		case field.Type == "google.protobuf.Timestamp":
+			imports["google/protobuf/timestamp.proto"] = true
+		case strings.HasPrefix(field.Type, "google.protobuf."):
+			imports["google/protobuf/wrappers.proto"] = true
+		}
+	}
+	for path := range imports {
+		data.Imports = append(data.Imports, path)
+	}
+	sort.Strings(data.Imports)
+
+	numbers := parseProtoNumbers(previous)
+	data.Reserved, data.ReservedNames = numbers.assign(data.Fields)
+
+	builder := strings.Builder{}
+	if err := protoTmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// ParseProtoToWrite .
+func ParseProtoToWrite(table *Table, previous []byte, writer io.Writer, options ...Option) error {
+	code, err := MakeProto(table, previous, options...)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, code)
+	return err
+}
+
+// makeProtoFields walks the columns like the go struct, with plain types since nullable columns become wrappers.
+// A column is nullable as in the go struct, when NULL is declared (mysql) or the column accepts NULL (others)
+// and null types are not disabled, so a wrapper always stands for a sql.NullXXX or pointer field.
+// The fields are in the column order, without numbers.
+func makeProtoFields(table *Table, opt options) (TmplData, []protoField) {
+	nullTypes := opt.NullStyle != NullDisable
+	opt.NullStyle = NullDisable
+	opt.TypeMapping = nil
+	opt.Associations = false
+	model, _ := makeTmplData(table, opt)
+
+	enums := make(map[string]TmplEnum, len(model.Enums))
+	for _, enum := range model.Enums {
+		enums[enum.Column] = enum
+	}
+	fields := make([]protoField, 0, len(model.Fields))
+	for i, f := range model.Fields {
+		field := protoField{Name: protoFieldName(f, opt), Comment: strings.Join(strings.Fields(f.Comment), " ")}
+		if enum, ok := enums[f.ColumnName]; ok {
+			field.Type = enum.Name
+			field.Repeated = enum.Set
+			field.Enum = true
+		} else if elem, ok := protoArrayTypes[f.GoType]; ok {
+			field.Type = elem
+			field.Repeated = true
+		} else {
+			field.Type = protoTypes[f.GoType]
+			if field.Type == "" {
+				field.Type = "string"
+			}
+			if wrapper, ok := protoWrappers[field.Type]; ok && nullTypes && table.Columns[i].Nullable {
+				field.Type = wrapper
+			}
+		}
+		fields = append(fields, field)
+	}
+	return model, fields
+}
+
+// protoFieldName is the column name without the column prefix, or the go field name in snake case
+// if the column name is not a lower case identifier.
+func protoFieldName(f TmplField, opt options) string {
+	name := strings.TrimPrefix(f.ColumnName, opt.ColumnPrefix)
+	if protoNameRe.MatchString(name) {
+		return name
+	}
+	return toSnake(f.Name)
+}
+
+// makeProtoEnum names the values after the enum in upper snake case, e.g. ORDER_STATUS_PAID.
+func makeProtoEnum(enum TmplEnum) protoEnum {
+	e := protoEnum{
+		Name:   enum.Name,
+		Column: enum.Column,
+		Set:    enum.Set,
+		Values: []protoEnumValue{{Name: strings.ToUpper(toSnake(enum.Name)) + "_UNSPECIFIED"}},
+	}
+	for i, v := range enum.Values {
+		e.Values = append(e.Values, protoEnumValue{Name: strings.ToUpper(toSnake(v.Name)), Number: i + 1})
+	}
+	return e
+}
+
+// parseProtoNumbers reads the field numbers and reserved fields of the first message of a proto file.
+func parseProtoNumbers(previous []byte) protoNumbers {
+	numbers := protoNumbers{
+		Fields:        make(map[string]int),
+		Types:         make(map[string]string),
+		Reserved:      make(map[int]bool),
+		ReservedNames: make(map[string]bool),
+	}
+	inMessage := false
+	for _, line := range strings.Split(string(previous), "\n") {
+		if !inMessage {
+			inMessage = protoMessageRe.MatchString(line)
+			continue
+		}
+		if strings.HasPrefix(line, "}") {
+			break
+		}
+		if m := protoFieldRe.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[3])
+			numbers.Fields[m[2]] = n
+			numbers.Types[m[2]] = strings.Join(strings.Fields(m[1]), " ")
+			numbers.Order = append(numbers.Order, m[2])
+			continue
+		}
+		if m := protoReservedRe.FindStringSubmatch(line); m != nil {
+			for _, part := range strings.Split(m[1], ",") {
+				part = strings.TrimSpace(part)
+				if name, err := strconv.Unquote(part); err == nil {
+					numbers.ReservedNames[name] = true
+					continue
+				}
+				from, to := part, part
+				if i := strings.Index(part, " to "); i >= 0 {
+					from, to = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+4:])
+				}
+				start, err1 := strconv.Atoi(from)
+				end, err2 := strconv.Atoi(to)
+				for n := start; err1 == nil && err2 == nil && n <= end; n++ {
+					numbers.Reserved[n] = true
+				}
+			}
+		}
+	}
+	return numbers
+}
+
+// assign numbers the fields: a field keeps its previous number, a new field takes the next unused one.
+// A field whose type changed, e.g. string to google.protobuf.StringValue, is not wire compatible:
+// its previous number is reserved and it takes a new one. The numbers and names of the fields gone
+// since the previous file are reserved too, the reserved statements are returned.
+func (p protoNumbers) assign(fields []protoField) (reserved, reservedNames string) {
+	next := 1
+	for n := range p.Reserved {
+		if n >= next {
+			next = n + 1
+		}
+	}
+	for _, n := range p.Fields {
+		if n >= next {
+			next = n + 1
+		}
+	}
+
+	present := make(map[string]bool, len(fields))
+	for i := range fields {
+		f := &fields[i]
+		present[f.Name] = true
+		if n, ok := p.Fields[f.Name]; ok {
+			if typ, ok := p.Types[f.Name]; !ok || typ == f.fullType() {
+				f.Number = n
+				continue
+			}
+			p.Reserved[n] = true
+		}
+		f.Number = next
+		next++
+	}
+
+	for _, name := range p.Order {
+		if !present[name] {
+			p.Reserved[p.Fields[name]] = true
+			p.ReservedNames[name] = true
+		}
+	}
+	// a column added back gets a new number, its name is no longer reserved
+	for name := range present {
+		delete(p.ReservedNames, name)
+	}
+
+	nums := make([]int, 0, len(p.Reserved))
+	for n := range p.Reserved {
+		nums = append(nums, n)
+	}
+	sort.Ints(nums)
+	parts := make([]string, 0, len(nums))
+	for _, n := range nums {
+		parts = append(parts, strconv.Itoa(n))
+	}
+	names := make([]string, 0, len(p.ReservedNames))
+	for name := range p.ReservedNames {
+		names = append(names, strconv.Quote(name))
+	}
+	sort.Strings(names)
+	return strings.Join(parts, ", "), strings.Join(names, ", ")
+}
+
+func init() {
+	protoTmplRaw = `// Code generated by gmodel. Field numbers are kept from the previous file, do not renumber them.
+
+syntax = "proto3";
+
+package {{.Package}};
+{{- if .Imports}}
+{{range .Imports}}
+import "{{.}}";
+{{- end}}
+{{- end}}
+{{- if .GoPackage}}
+
+option go_package = "{{.GoPackage}}";
+{{- end}}
+
+// {{.Comment}}
+{{- if .View}}
+// {{.Message}} is read-only, it maps the view.
+{{- end}}
+message {{.Message}} {
+{{- range .Fields}}
+  {{if .Repeated}}repeated {{end}}{{.Type}} {{.Name}} = {{.Number}};{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}
+{{- if .Reserved}}
+
+  reserved {{.Reserved}};
+{{- end}}
+{{- if .ReservedNames}}
+  reserved {{.ReservedNames}};
+{{- end}}
+}
+{{- range .Enums}}
+
+// {{.Name}} is {{if .Set}}an element of set column {{.Column}}{{else}}the value of enum column {{.Column}}, 0 is NULL{{end}}.
+enum {{.Name}} {
+{{- range .Values}}
+  {{.Name}} = {{.Number}};
+{{- end}}
+}
+{{- end}}
+`
+}
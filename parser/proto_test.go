@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMakeProto(t *testing.T) {
+	tables, err := GetTablesFromSQL("CREATE TABLE `orders` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `status` enum('new','paid') NOT NULL,\n" +
+		"  `tags` set('gift','urgent') DEFAULT NULL,\n" +
+		"  `note` varchar(255) NULL DEFAULT NULL COMMENT 'buyer note',\n" +
+		"  `amount` decimal(10,2) NOT NULL,\n" +
+		"  `paid_at` datetime DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") COMMENT='订单';")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := MakeProto(tables[0], nil, WithPackage("shop"), WithProtoGoPackage("example.com/app/pb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`syntax = "proto3";`,
+		"package shop;",
+		`import "google/protobuf/timestamp.proto";`,
+		`import "google/protobuf/wrappers.proto";`,
+		`option go_package = "example.com/app/pb";`,
+		"// Orders 订单\nmessage Orders {",
+		"  int64 id = 1;\n",
+		"  OrderStatus status = 2;\n",
+		"  repeated OrderTags tags = 3;\n",
+		"  google.protobuf.StringValue note = 4; // buyer note\n",
+		"  string amount = 5;\n",
+		"  google.protobuf.Timestamp paid_at = 6;\n",
+		"enum OrderStatus {\n  ORDER_STATUS_UNSPECIFIED = 0;\n  ORDER_STATUS_NEW = 1;\n  ORDER_STATUS_PAID = 2;\n}",
+		"enum OrderTags {\n  ORDER_TAGS_UNSPECIFIED = 0;\n  ORDER_TAGS_GIFT = 1;\n",
+	} {
+		if !strings.Contains(first, s) {
+			t.Errorf("proto should contain %q:\n%s", s, first)
+		}
+	}
+
+	// note is dropped and currency added: the numbers are kept, 4 and note are reserved
+	tables, err = GetTablesFromSQL("CREATE TABLE `orders` (\n" +
+		"  `currency` char(3) NOT NULL,\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `status` enum('new','paid') NOT NULL,\n" +
+		"  `tags` set('gift','urgent') DEFAULT NULL,\n" +
+		"  `amount` decimal(10,2) NOT NULL,\n" +
+		"  `paid_at` datetime DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") COMMENT='订单';")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := MakeProto(tables[0], []byte(first), WithPackage("shop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"  string currency = 7;\n  int64 id = 1;\n  OrderStatus status = 2;\n",
+		"  google.protobuf.Timestamp paid_at = 6;\n\n  reserved 4;\n  reserved \"note\";\n}",
+	} {
+		if !strings.Contains(second, s) {
+			t.Errorf("regenerated proto should contain %q:\n%s", s, second)
+		}
+	}
+	if again, _ := MakeProto(tables[0], []byte(second), WithPackage("shop")); again != second {
+		t.Errorf("regenerating should not change the file:\n%s\n---\n%s", second, again)
+	}
+}
+
+func TestMakeProtoTypeChange(t *testing.T) {
+	// DEFAULT NULL without NULL is a plain string in the go struct and in the message
+	tables, err := GetTablesFromSQL("CREATE TABLE `users` (`id` bigint NOT NULL, `name` varchar(20) DEFAULT NULL, PRIMARY KEY (`id`));")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := MakeProto(tables[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(first, "  string name = 2;\n") {
+		t.Errorf("name should be a plain string:\n%s", first)
+	}
+
+	// NULL turns name into a wrapper, a new number on the wire
+	tables, err = GetTablesFromSQL("CREATE TABLE `users` (`id` bigint NOT NULL, `name` varchar(20) NULL, PRIMARY KEY (`id`));")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := MakeProto(tables[0], []byte(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(second, "  google.protobuf.StringValue name = 3;\n\n  reserved 2;\n}") {
+		t.Errorf("the number of name should change and 2 be reserved:\n%s", second)
+	}
+	if again, _ := MakeProto(tables[0], []byte(second)); again != second {
+		t.Errorf("regenerating should not change the file:\n%s\n---\n%s", second, again)
+	}
+}
+
+func TestParseProtoNumbers(t *testing.T) {
+	numbers := parseProtoNumbers([]byte("syntax = \"proto3\";\n\nmessage Users {\n" +
+		"  int64 id = 1;\n" +
+		"  repeated string tags = 3 [packed = true];\n" +
+		"  reserved 2, 9 to 11;\n" +
+		"  reserved \"name\";\n" +
+		"}\n\nenum UserType {\n  USER_TYPE_UNSPECIFIED = 0;\n}\n"))
+	if numbers.Fields["id"] != 1 || numbers.Fields["tags"] != 3 || len(numbers.Fields) != 2 || numbers.Types["tags"] != "repeated string" {
+		t.Errorf("fields = %v", numbers.Fields)
+	}
+	if len(numbers.Reserved) != 4 || !numbers.Reserved[2] || !numbers.Reserved[10] || !numbers.ReservedNames["name"] {
+		t.Errorf("reserved = %v %v", numbers.Reserved, numbers.ReservedNames)
+	}
+
+	fields := []protoField{{Name: "id", Type: "int64"}, {Name: "name", Type: "string"}, {Name: "email", Type: "string"}}
+	reserved, names := numbers.assign(fields)
+	if fields[0].Number != 1 || fields[1].Number != 12 || fields[2].Number != 13 {
+		t.Errorf("numbers = %v", fields)
+	}
+	if reserved != "2, 3, 9, 10, 11" || names != `"tags"` {
+		t.Errorf("reserved = %s; %s", reserved, names)
+	}
+}
@@ -0,0 +1,283 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/blastrain/vitess-sqlparser/tidbparser/ast"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/parser"
+	"github.com/pkg/errors"
+)
+
+// Schema is an in-memory set of tables built by replaying DDL statements in order.
+type Schema struct {
+	tables []*Table
+	opt    options
+}
+
+// NewSchema .
+func NewSchema(options ...Option) *Schema {
+	return &Schema{opt: parseOption(options)}
+}
+
+// Tables returns the tables in creation order.
+func (s *Schema) Tables() []*Table {
+	return s.tables
+}
+
+// Table returns the table named name, nil if not exists.
+func (s *Schema) Table(name string) *Table {
+	if i := s.index(name); i >= 0 {
+		return s.tables[i]
+	}
+	return nil
+}
+
+// index .
+func (s *Schema) index(name string) int {
+	for i, t := range s.tables {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Apply replays CREATE/ALTER/DROP/RENAME TABLE and CREATE/DROP INDEX statements of sql, other statements are skipped.
+func (s *Schema) Apply(sql string) error {
+	stmts, err := parser.New().Parse(stripLineComments(sql), s.opt.Charset, s.opt.Collation)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if err = s.applyStmt(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyStmt .
+func (s *Schema) applyStmt(stmt ast.StmtNode) error {
+	switch st := stmt.(type) {
+	case *ast.CreateTableStmt:
+		name := st.Table.Name.String()
+		i := s.index(name)
+		if i >= 0 && st.IfNotExists {
+			return nil
+		}
+		table := tableFromCreateStmt(st)
+		if st.ReferTable != nil {
+			refer := s.Table(st.ReferTable.Name.String())
+			if refer == nil {
+				return errors.Errorf("create table %s like: table(%s) not found", name, st.ReferTable.Name.String())
+			}
+			table = refer.clone()
+			table.Name = name
+		}
+		if i >= 0 {
+			s.tables[i] = table
+		} else {
+			s.tables = append(s.tables, table)
+		}
+	case *ast.DropTableStmt:
+		for _, t := range st.Tables {
+			i := s.index(t.Name.String())
+			if i < 0 {
+				if st.IfExists {
+					continue
+				}
+				return errors.Errorf("drop table: table(%s) not found", t.Name.String())
+			}
+			s.tables = append(s.tables[:i], s.tables[i+1:]...)
+		}
+	case *ast.RenameTableStmt:
+		for _, tt := range st.TableToTables {
+			if err := s.rename(tt.OldTable.Name.String(), tt.NewTable.Name.String()); err != nil {
+				return err
+			}
+		}
+	case *ast.CreateIndexStmt:
+		table := s.Table(st.Table.Name.String())
+		if table == nil {
+			return errors.Errorf("create index: table(%s) not found", st.Table.Name.String())
+		}
+		index := &Index{Name: st.IndexName, Unique: st.Unique}
+		for _, key := range st.IndexColNames {
+			index.Columns = append(index.Columns, key.Column.Name.String())
+		}
+		return errors.WithMessagef(table.addIndex(index), "create index on %s", table.Name)
+	case *ast.DropIndexStmt:
+		table := s.Table(st.Table.Name.String())
+		if table == nil {
+			return errors.Errorf("drop index: table(%s) not found", st.Table.Name.String())
+		}
+		if err := table.dropIndex(st.IndexName); err != nil && !st.IfExists {
+			return errors.WithMessagef(err, "drop index on %s", table.Name)
+		}
+	case *ast.AlterTableStmt:
+		table := s.Table(st.Table.Name.String())
+		if table == nil {
+			return errors.Errorf("alter table: table(%s) not found", st.Table.Name.String())
+		}
+		for _, spec := range st.Specs {
+			if err := s.alter(table, spec); err != nil {
+				return errors.WithMessagef(err, "alter table %s", table.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// stripLineComments blanks the full-line -- comments, the parser rejects a bare "--" (mysqldump writes them
+// around every table) while mysql accepts it at the end of a line. The lines are kept for the error positions.
+func stripLineComments(sql string) string {
+	lines := strings.Split(sql, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rename .
+func (s *Schema) rename(oldName, newName string) error {
+	table := s.Table(oldName)
+	if table == nil {
+		return errors.Errorf("rename table: table(%s) not found", oldName)
+	}
+	if s.Table(newName) != nil {
+		return errors.Errorf("rename table: table(%s) already exists", newName)
+	}
+	table.Name = newName
+	for _, t := range s.tables {
+		for _, fk := range t.ForeignKeys {
+			if fk.RefTable == oldName {
+				fk.RefTable = newName
+			}
+		}
+	}
+	return nil
+}
+
+// alter applies a single ALTER TABLE specification.
+func (s *Schema) alter(table *Table, spec *ast.AlterTableSpec) error {
+	switch spec.Tp {
+	case ast.AlterTableOption:
+		for _, opt := range spec.Options {
+			if opt.Tp == ast.TableOptionComment {
+				table.Comment = opt.StrValue
+			}
+		}
+	case ast.AlterTableAddColumns:
+		for i, def := range spec.NewColumns {
+			if table.column(def.Name.Name.String()) >= 0 {
+				return errors.Errorf("column(%s) already exists", def.Name.Name.String())
+			}
+			position := spec.Position
+			if i > 0 {
+				position = nil
+			}
+			if err := table.insertColumn(columnFromDef(def, nil), position); err != nil {
+				return err
+			}
+			if fk := foreignKeyFromColumn(def); fk != nil {
+				table.ForeignKeys = append(table.ForeignKeys, fk)
+			}
+		}
+	case ast.AlterTableAddConstraint:
+		if index := indexFromConstraint(spec.Constraint); index != nil {
+			return table.addIndex(index)
+		}
+		if fk := foreignKeyFromConstraint(spec.Constraint); fk != nil {
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+			return nil
+		}
+		if spec.Constraint.Tp == ast.ConstraintPrimaryKey {
+			for _, key := range spec.Constraint.Keys {
+				i := table.column(key.Column.Name.String())
+				if i < 0 {
+					return errors.Errorf("column(%s) not found", key.Column.Name.String())
+				}
+				table.Columns[i].PrimaryKey = true
+			}
+		}
+	case ast.AlterTableDropColumn:
+		i := table.column(spec.OldColumnName.Name.String())
+		if i < 0 {
+			return errors.Errorf("column(%s) not found", spec.OldColumnName.Name.String())
+		}
+		table.Columns = append(table.Columns[:i], table.Columns[i+1:]...)
+		table.renameIndexColumn(spec.OldColumnName.Name.String(), "")
+	case ast.AlterTableDropPrimaryKey:
+		for _, col := range table.Columns {
+			col.PrimaryKey = false
+		}
+	case ast.AlterTableModifyColumn, ast.AlterTableChangeColumn:
+		oldName := spec.NewColumns[0].Name.Name.String()
+		if spec.Tp == ast.AlterTableChangeColumn {
+			oldName = spec.OldColumnName.Name.String()
+		}
+		i := table.column(oldName)
+		if i < 0 {
+			return errors.Errorf("column(%s) not found", oldName)
+		}
+		col := columnFromDef(spec.NewColumns[0], nil)
+		col.PrimaryKey = col.PrimaryKey || table.Columns[i].PrimaryKey
+		table.Columns = append(table.Columns[:i], table.Columns[i+1:]...)
+		table.renameIndexColumn(oldName, col.Name)
+		if spec.Position == nil || spec.Position.Tp == ast.ColumnPositionNone {
+			table.Columns = append(table.Columns[:i], append([]*Column{col}, table.Columns[i:]...)...)
+			return nil
+		}
+		return table.insertColumn(col, spec.Position)
+	case ast.AlterTableAlterColumn:
+		def := spec.NewColumns[0]
+		i := table.column(def.Name.Name.String())
+		if i < 0 {
+			return errors.Errorf("column(%s) not found", def.Name.Name.String())
+		}
+		// SET DEFAULT carries the value as an option without type, DROP DEFAULT carries nothing
+		table.Columns[i].Default = ""
+		for _, o := range def.Options {
+			if o.Expr != nil {
+				table.Columns[i].Default = getDefaultValue(o.Expr)
+			}
+		}
+	case ast.AlterTableDropIndex:
+		return table.dropIndex(spec.Name)
+	case ast.AlterTableDropForeignKey:
+		return table.dropForeignKey(spec.Name)
+	case ast.AlterTableRenameTable:
+		return s.rename(table.Name, spec.NewTable.Name.String())
+	}
+	return nil
+}
+
+// GetTablesFromMigrations replays the *.sql files of dir in file name order (0001_init.sql, 0002_add_col.sql ...)
+// and returns the final tables, *.down.sql files are skipped.
+func GetTablesFromMigrations(dir string, options ...Option) ([]*Table, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	schema := NewSchema(options...)
+	for _, file := range files {
+		if strings.HasSuffix(file, ".down.sql") {
+			continue
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err = schema.Apply(string(b)); err != nil {
+			return nil, errors.WithMessagef(err, "apply %s", filepath.Base(file))
+		}
+	}
+	return schema.Tables(), nil
+}
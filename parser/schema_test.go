@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func columnNames(table *Table) string {
+	names := make([]string, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		names = append(names, col.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestSchemaApply(t *testing.T) {
+	schema := NewSchema()
+	for _, sql := range []string{
+		"CREATE TABLE `users` (`id` int NOT NULL, `name` varchar(20), `tmp` int, PRIMARY KEY (`id`));",
+		"ALTER TABLE `users` ADD COLUMN `email` varchar(64) NOT NULL AFTER `id`, DROP COLUMN `tmp`;",
+		"ALTER TABLE `users` MODIFY COLUMN `name` varchar(64) NOT NULL DEFAULT '' FIRST;",
+		"ALTER TABLE `users` CHANGE COLUMN `email` `mail` varchar(128) NOT NULL, COMMENT = 'user table';",
+		"ALTER TABLE `users` ALTER COLUMN `name` SET DEFAULT 'guest';",
+		"CREATE TABLE `logs` (`id` int);",
+		"CREATE TABLE `logs_bak` LIKE `logs`;",
+		"DROP TABLE `logs`;",
+		"RENAME TABLE `logs_bak` TO `audit_logs`;",
+		"ALTER TABLE `audit_logs` ADD PRIMARY KEY (`id`);",
+	} {
+		if err := schema.Apply(sql); err != nil {
+			t.Fatalf("apply %q: %s", sql, err)
+		}
+	}
+
+	tables := schema.Tables()
+	if len(tables) != 2 || tables[0].Name != "users" || tables[1].Name != "audit_logs" {
+		t.Fatalf("unexpected tables: %v", tables)
+	}
+	users := tables[0]
+	if got := columnNames(users); got != "name,id,mail" {
+		t.Errorf("unexpected columns: %s", got)
+	}
+	if users.Comment != "user table" {
+		t.Errorf("unexpected comment: %q", users.Comment)
+	}
+	if col := users.Columns[0]; col.Default != "guest" || !col.NotNull || col.Tp.Flen != 64 {
+		t.Errorf("unexpected name column: %+v", col)
+	}
+	if !users.Columns[1].PrimaryKey {
+		t.Error("id should stay primary key")
+	}
+	if !tables[1].Columns[0].PrimaryKey {
+		t.Error("audit_logs.id should be primary key")
+	}
+
+	for _, sql := range []string{
+		"CREATE TABLE `posts` (`id` int NOT NULL, `uid` int NOT NULL, `editor` int, CONSTRAINT `fk_uid` FOREIGN KEY (`uid`) REFERENCES `users` (`id`));",
+		"ALTER TABLE `posts` ADD CONSTRAINT `fk_editor` FOREIGN KEY (`editor`) REFERENCES `users` (`id`);",
+		"ALTER TABLE `posts` CHANGE COLUMN `uid` `user_id` int NOT NULL;",
+		"ALTER TABLE `posts` DROP FOREIGN KEY `fk_editor`;",
+		"RENAME TABLE `users` TO `members`;",
+	} {
+		if err := schema.Apply(sql); err != nil {
+			t.Fatalf("apply %q: %s", sql, err)
+		}
+	}
+	fks := schema.Table("posts").ForeignKeys
+	if len(fks) != 1 || fks[0].Name != "fk_uid" || fks[0].Columns[0] != "user_id" || fks[0].RefTable != "members" {
+		t.Errorf("unexpected foreign keys: %+v", fks)
+	}
+
+	for _, sql := range []string{
+		"ALTER TABLE `missing` ADD COLUMN `x` int;",
+		"DROP TABLE `missing`;",
+		"ALTER TABLE `users` DROP COLUMN `missing`;",
+	} {
+		if err := schema.Apply(sql); err == nil {
+			t.Errorf("apply %q should fail", sql)
+		}
+	}
+}
+
+func TestGetTablesFromMigrations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"0001_init.sql":         "--\n-- orders\n--\nCREATE TABLE `orders` (`id` int NOT NULL AUTO_INCREMENT PRIMARY KEY);",
+		"0002_add_col.sql":      "ALTER TABLE `orders` ADD COLUMN `amount` decimal(10,2) NOT NULL;",
+		"0002_add_col.down.sql": "ALTER TABLE `orders` DROP COLUMN `amount`;",
+		"0003_rename.sql":       "ALTER TABLE `orders` RENAME TO `purchases`;",
+		"README.md":             "not a migration",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tables, err := GetTablesFromMigrations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || tables[0].Name != "purchases" || columnNames(tables[0]) != "id,amount" {
+		t.Fatalf("unexpected tables: %+v", tables)
+	}
+}
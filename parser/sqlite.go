@@ -0,0 +1,342 @@
+package parser
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/mysql"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/types"
+	_ "github.com/mattn/go-sqlite3" // "sqlite3"
+	"github.com/pkg/errors"
+)
+
+const sqliteTablesSQL = `SELECT name FROM sqlite_master
+WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+ORDER BY name`
+
+const sqliteViewsSQL = `SELECT name FROM sqlite_master
+WHERE type = 'view'
+ORDER BY name`
+
+// sqliteDSN strips the sqlite:// scheme, the rest is a file path or a file: uri.
+func sqliteDSN(dsn string) string {
+	for _, scheme := range []string{"sqlite3://", "sqlite://"} {
+		if strings.HasPrefix(strings.ToLower(dsn), scheme) {
+			return dsn[len(scheme):]
+		}
+	}
+	return dsn
+}
+
+// openSQLite .
+func openSQLite(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", sqliteDSN(dsn))
+	if err != nil {
+		return nil, errors.WithMessage(err, "open db error")
+	}
+	return db, nil
+}
+
+// getSQLiteTables .
+func getSQLiteTables(dsn string) ([]string, error) {
+	db, err := openSQLite(dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return sqliteTables(context.Background(), db)
+}
+
+// sqliteTables .
+func sqliteTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	return sqliteMasterNames(ctx, db, sqliteTablesSQL)
+}
+
+// sqliteViews .
+func sqliteViews(ctx context.Context, db *sql.DB) ([]string, error) {
+	return sqliteMasterNames(ctx, db, sqliteViewsSQL)
+}
+
+// sqliteMasterNames .
+func sqliteMasterNames(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.WithMessage(err, "query tables error")
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// GetTableFromSQLite reads tableName through PRAGMA table_info and index_list.
+func GetTableFromSQLite(dsn, tableName string) (*Table, error) {
+	db, err := openSQLite(dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return sqliteTable(context.Background(), db, tableName)
+}
+
+// sqliteTable .
+func sqliteTable(ctx context.Context, db *sql.DB, tableName string) (*Table, error) {
+	var tableType string
+	err := db.QueryRowContext(ctx, "SELECT type FROM sqlite_master WHERE type IN ('table', 'view') AND name = ?", tableName).Scan(&tableType)
+	if err == sql.ErrNoRows {
+		return nil, errors.Errorf("table(%s) not found", tableName)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "query sqlite_master error")
+	}
+
+	table := &Table{Name: tableName, Driver: DriverSQLite, View: tableType == "view"}
+	rows, err := db.QueryContext(ctx, "SELECT name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?)", tableName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "query table info error")
+	}
+	defer rows.Close()
+	pkCount := 0
+	for rows.Next() {
+		var (
+			col          Column
+			notNull      bool
+			defaultValue sql.NullString
+			pk           int
+		)
+		if err = rows.Scan(&col.Name, &col.SQLType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, err
+		}
+		col.DBType = strings.ToUpper(col.SQLType)
+		col.PrimaryKey = pk > 0
+		col.NotNull = notNull || col.PrimaryKey
+		col.Nullable = !col.NotNull
+		col.Default = unquoteDefault(defaultValue.String)
+		col.Tp = sqliteFieldType(col.DBType)
+		if col.PrimaryKey {
+			pkCount++
+		}
+		table.Columns = append(table.Columns, &col)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// INTEGER PRIMARY KEY is an alias of rowid
+	for _, col := range table.Columns {
+		if col.PrimaryKey && pkCount == 1 && col.DBType == "INTEGER" {
+			col.AutoIncrement = true
+		}
+	}
+
+	if err = sqliteIndexes(ctx, db, table); err != nil {
+		return nil, err
+	}
+	if err = sqliteForeignKeys(ctx, db, table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// sqliteForeignKeys reads the foreign keys of table, they are unnamed in sqlite so fk_<table>_<id> is used.
+func sqliteForeignKeys(ctx context.Context, db *sql.DB, table *Table) error {
+	rows, err := db.QueryContext(ctx, "SELECT id, \"table\", \"from\", \"to\" FROM pragma_foreign_key_list(?) ORDER BY id, seq", table.Name)
+	if err != nil {
+		return errors.WithMessage(err, "query foreign key list error")
+	}
+	defer rows.Close()
+	var fk *ForeignKey
+	lastID := -1
+	for rows.Next() {
+		var (
+			id             int
+			refTable, from string
+			to             sql.NullString
+		)
+		if err = rows.Scan(&id, &refTable, &from, &to); err != nil {
+			return err
+		}
+		if fk == nil || id != lastID {
+			fk = &ForeignKey{Name: fmt.Sprintf("fk_%s_%d", table.Name, id), RefTable: refTable}
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+			lastID = id
+		}
+		fk.Columns = append(fk.Columns, from)
+		if to.Valid {
+			fk.RefColumns = append(fk.RefColumns, to.String)
+		}
+	}
+	return rows.Err()
+}
+
+// sqliteIndexes reads the indexes of table, an unnamed UNIQUE constraint on a single column marks the column unique.
+func sqliteIndexes(ctx context.Context, db *sql.DB, table *Table) error {
+	rows, err := db.QueryContext(ctx, "SELECT name, \"unique\", origin FROM pragma_index_list(?) WHERE origin != 'pk' ORDER BY seq DESC", table.Name)
+	if err != nil {
+		return errors.WithMessage(err, "query index list error")
+	}
+	var indexes []*Index
+	var origins []string
+	for rows.Next() {
+		var (
+			index  Index
+			origin string
+		)
+		if err = rows.Scan(&index.Name, &index.Unique, &origin); err != nil {
+			rows.Close()
+			return err
+		}
+		indexes = append(indexes, &index)
+		origins = append(origins, origin)
+	}
+	rows.Close()
+
+	for i, index := range indexes {
+		infoRows, err := db.QueryContext(ctx, "SELECT name FROM pragma_index_info(?) ORDER BY seqno", index.Name)
+		if err != nil {
+			return errors.WithMessage(err, "query index info error")
+		}
+		expression := false
+		for infoRows.Next() {
+			var name sql.NullString
+			if err = infoRows.Scan(&name); err != nil {
+				infoRows.Close()
+				return err
+			}
+			expression = expression || !name.Valid
+			index.Columns = append(index.Columns, name.String)
+		}
+		infoRows.Close()
+		if expression || len(index.Columns) == 0 {
+			continue
+		}
+		if origins[i] == "u" {
+			if len(index.Columns) == 1 {
+				if col := findColumn(table, index.Columns[0]); col != nil {
+					col.Unique = true
+				}
+				continue
+			}
+			// sqlite_autoindex_* names are reserved, name it like mysql does
+			index.Name = index.Columns[0]
+		}
+		table.Indexes = append(table.Indexes, index)
+	}
+	return nil
+}
+
+// sqliteAffinity returns the type affinity of a declared type, see https://www.sqlite.org/datatype3.html
+func sqliteAffinity(declared string) string {
+	switch {
+	case strings.Contains(declared, "INT"):
+		return "INTEGER"
+	case strings.Contains(declared, "CHAR"), strings.Contains(declared, "CLOB"), strings.Contains(declared, "TEXT"):
+		return "TEXT"
+	case strings.Contains(declared, "BLOB"), declared == "":
+		return "BLOB"
+	case strings.Contains(declared, "REAL"), strings.Contains(declared, "FLOA"), strings.Contains(declared, "DOUB"):
+		return "REAL"
+	default:
+		return "NUMERIC"
+	}
+}
+
+// sqliteKind refines the affinity by the declared name for types go has a better fit for.
+func sqliteKind(declared string) string {
+	switch {
+	case strings.HasPrefix(declared, "BOOL"):
+		return "BOOL"
+	case strings.HasPrefix(declared, "DATE"), strings.HasPrefix(declared, "TIMESTAMP"):
+		return "TIME"
+	case strings.HasPrefix(declared, "DECIMAL"):
+		return "DECIMAL"
+	case declared == "JSON":
+		return "JSON"
+	default:
+		return sqliteAffinity(declared)
+	}
+}
+
+// sqliteFieldType maps a declared sqlite type to the closest mysql field type.
+func sqliteFieldType(declared string) *types.FieldType {
+	var ft *types.FieldType
+	switch sqliteKind(declared) {
+	case "BOOL":
+		ft = types.NewFieldType(mysql.TypeTiny)
+		ft.Flen = 1
+	case "TIME":
+		ft = types.NewFieldType(mysql.TypeDatetime)
+		if declared == "DATE" {
+			ft = types.NewFieldType(mysql.TypeDate)
+		}
+	case "DECIMAL":
+		ft = types.NewFieldType(mysql.TypeNewDecimal)
+	case "JSON":
+		ft = types.NewFieldType(mysql.TypeJSON)
+	case "INTEGER":
+		ft = types.NewFieldType(mysql.TypeLonglong)
+	case "TEXT":
+		ft = types.NewFieldType(mysql.TypeBlob)
+	case "REAL", "NUMERIC":
+		ft = types.NewFieldType(mysql.TypeDouble)
+	default:
+		ft = types.NewFieldType(mysql.TypeBlob)
+		ft.Flag |= mysql.BinaryFlag
+	}
+	return ft
+}
+
+// sqliteToGoType .
+func sqliteToGoType(col *Column, style NullStyle) (name string, path string) {
+	kind := sqliteKind(col.DBType)
+	if kind == "BLOB" {
+		return "[]byte", ""
+	}
+
+	if style == NullInSQL {
+		path = "database/sql"
+		switch kind {
+		case "BOOL":
+			name = "sql.NullBool"
+		case "TIME":
+			name = "sql.NullTime"
+		case "INTEGER":
+			name = "sql.NullInt64"
+		case "REAL", "NUMERIC", "DECIMAL":
+			name = "sql.NullFloat64"
+		default:
+			name = "sql.NullString"
+		}
+		return
+	}
+
+	switch kind {
+	case "BOOL":
+		name = "bool"
+	case "TIME":
+		path = "time"
+		name = "time.Time"
+	case "INTEGER":
+		name = "int64"
+	case "REAL", "NUMERIC":
+		name = "float64"
+	case "DECIMAL":
+		path = "github.com/shopspring/decimal"
+		name = "decimal.Decimal"
+	default:
+		name = "string"
+	}
+
+	if style == NullInPointer {
+		name = "*" + name
+	}
+	return
+}
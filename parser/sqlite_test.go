@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSQLiteDB creates a database file with the statements executed.
+func newSQLiteDB(t *testing.T, stmts ...string) string {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range stmts {
+		if _, err = db.Exec(stmt); err != nil {
+			t.Fatalf("exec %q: %s", stmt, err)
+		}
+	}
+	return dsn
+}
+
+func TestSQLiteEndToEnd(t *testing.T) {
+	dsn := newSQLiteDB(t,
+		`CREATE TABLE users (
+			id INTEGER PRIMARY KEY,
+			email VARCHAR(128) NOT NULL,
+			nickname TEXT,
+			active BOOLEAN NOT NULL DEFAULT 1,
+			balance DECIMAL(10,2) NOT NULL DEFAULT '0.00',
+			avatar BLOB,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE UNIQUE INDEX uk_email ON users (email)`,
+		`CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL, title TEXT NOT NULL UNIQUE)`,
+		`CREATE INDEX idx_user_title ON posts (user_id, title)`,
+	)
+
+	if DetectDriver(dsn) != DriverSQLite {
+		t.Fatalf("%s should be detected as sqlite", dsn)
+	}
+	tables, err := GetTables(DriverSQLite, dsn, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tables, ",") != "posts,users" {
+		t.Fatalf("unexpected tables: %v", tables)
+	}
+
+	codes, err := ParseTableFromDB(DriverSQLite, dsn, "users", WithJSONTag(), WithGormType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := codes.StructCode[0]
+	for field, goType := range map[string]string{
+		"ID":        "int64",
+		"Email":     "string",
+		"Nickname":  "sql.NullString",
+		"Active":    "bool",
+		"Balance":   "decimal.Decimal",
+		"Avatar":    "[]byte",
+		"CreatedAt": "time.Time",
+	} {
+		if !containsField(code, field, goType) {
+			t.Errorf("field %s %s not found in:\n%s", field, goType, code)
+		}
+	}
+	for _, s := range []string{
+		`gorm:"column:id;type:INTEGER;primaryKey;AUTO_INCREMENT"`,
+		`gorm:"column:email;type:VARCHAR(128);NOT NULL;uniqueIndex:uk_email"`,
+		`gorm:"column:balance;type:DECIMAL(10,2);default:0.00;NOT NULL"`,
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("%s not found in:\n%s", s, code)
+		}
+	}
+
+	codes, err = ParseTableFromDB(DriverSQLite, dsn, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`gorm:"column:user_id;NOT NULL;index:idx_user_title,priority:1"`,
+		`gorm:"column:title;unique;NOT NULL;index:idx_user_title,priority:2"`,
+	} {
+		if !strings.Contains(codes.StructCode[0], s) {
+			t.Errorf("%s not found in:\n%s", s, codes.StructCode[0])
+		}
+	}
+
+	if _, err = GetTableFromDB(DriverSQLite, dsn, "missing"); err == nil {
+		t.Error("missing table should fail")
+	}
+}
+
+func TestIntrospector(t *testing.T) {
+	stmts := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		stmts = append(stmts, fmt.Sprintf("CREATE TABLE t%02d (id INTEGER PRIMARY KEY, name TEXT NOT NULL)", i))
+	}
+	dsn := newSQLiteDB(t, stmts...)
+
+	in, err := NewIntrospector(DriverSQLite, dsn, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	names, err := in.Tables("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 20 {
+		t.Fatalf("got %d tables, want 20", len(names))
+	}
+	tables, err := in.ReadTables(context.Background(), names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, table := range tables {
+		if table.Name != names[i] || len(table.Columns) != 2 {
+			t.Errorf("table %d = %s with %d columns, want %s with 2", i, table.Name, len(table.Columns), names[i])
+		}
+	}
+	if open := in.db.Stats().OpenConnections; open > 3 {
+		t.Errorf("%d connections opened, want at most 3", open)
+	}
+
+	if _, err = in.ReadTables(context.Background(), []string{"t00", "missing"}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("missing table should fail, got %v", err)
+	}
+	if tables, err = in.BulkTables(context.Background(), nil); err != nil || len(tables) != 20 {
+		t.Errorf("BulkTables(nil) = %d tables, %v, want 20", len(tables), err)
+	}
+
+	// a canceled read stops with the error of ctx
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = in.ReadTables(ctx, names); err != context.Canceled {
+		t.Errorf("canceled ReadTables should fail with context.Canceled, got %v", err)
+	}
+	if _, err = in.BulkTables(ctx, nil); err != context.Canceled {
+		t.Errorf("canceled BulkTables should fail with context.Canceled, got %v", err)
+	}
+	if _, err = in.CreateTable("t00"); err == nil {
+		t.Error("show create table should fail on sqlite")
+	}
+}
+
+func TestSQLiteViews(t *testing.T) {
+	dsn := newSQLiteDB(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, deleted INTEGER NOT NULL DEFAULT 0)",
+		"CREATE VIEW active_users AS SELECT id, name FROM users WHERE deleted = 0",
+	)
+	in, err := NewIntrospector(DriverSQLite, dsn, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	names, err := in.Tables("*")
+	if err != nil || strings.Join(names, ",") != "users" {
+		t.Errorf("views should be skipped by default, got %v, %v", names, err)
+	}
+	in.IncludeViews(true)
+	if names, err = in.Tables("*"); err != nil || strings.Join(names, ",") != "active_users,users" {
+		t.Errorf("Tables with views = %v, %v", names, err)
+	}
+
+	view, err := in.Table("active_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !view.View || len(view.Columns) != 2 {
+		t.Fatalf("active_users = view %v with %d columns, want a view with 2", view.View, len(view.Columns))
+	}
+
+	buf := &bytes.Buffer{}
+	if err = ParseTablesToWrite([]*Table{view}, buf); err != nil {
+		t.Fatal(err)
+	}
+	code := buf.String()
+	for _, want := range []string{
+		"// ActiveUsers is read-only, it maps the view active_users.",
+		`gorm:"column:name;->"`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("model should contain %q:\n%s", want, code)
+		}
+	}
+
+	dao, err := MakeDao(view, WithModelImport("example.com/app/model"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{") Create(", ") Update(", ") Delete("} {
+		if strings.Contains(dao, method) {
+			t.Errorf("dao of a view should not have %s:\n%s", method, dao)
+		}
+	}
+	if !strings.Contains(dao, ") List(") {
+		t.Errorf("dao of a view should have List:\n%s", dao)
+	}
+
+	// the model is read back as a view and left out of migrations
+	dir := t.TempDir()
+	if err = os.WriteFile(filepath.Join(dir, "active_users.go"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	models, err := ParseModelDir(dir)
+	if err != nil || len(models) != 1 || !models[0].View {
+		t.Fatalf("ParseModelDir = %v, %v, want one view model", models, err)
+	}
+	migration, err := MigrateModels(models, nil)
+	if err != nil || len(migration.Up) != 0 {
+		t.Errorf("view should not be migrated, got %v, %v", migration.Up, err)
+	}
+}
@@ -0,0 +1,395 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blastrain/vitess-sqlparser/tidbparser/ast"
+	"github.com/blastrain/vitess-sqlparser/tidbparser/dependency/types"
+	"github.com/pkg/errors"
+)
+
+// Table is the dialect independent description of a table, makeCode renders it.
+type Table struct {
+	Name    string    `json:"-"`
+	Comment string    `json:"-"`
+	Driver  Driver    `json:"-"`
+	Columns []*Column `json:"-"`
+	Indexes []*Index  `json:"-"` // secondary indexes, the primary key is kept on the columns
+	// View marks a database view, its model is read-only
+	View bool `json:"-"`
+
+	ForeignKeys []*ForeignKey `json:"-"`
+}
+
+// Column .
+type Column struct {
+	Name string `json:"-"`
+	// Tp is the mysql field type, columns of other drivers are mapped to the closest one
+	Tp *types.FieldType `json:"-"`
+	// SQLType is the column type as the database prints it, e.g. varchar(64) or timestamp with time zone
+	SQLType string `json:"-"`
+	// DBType is the raw type name reported by non mysql drivers, e.g. uuid, jsonb, _int4
+	DBType        string `json:"-"`
+	NotNull       bool   `json:"-"`
+	Nullable      bool   `json:"-"` // NULL is written explicitly (mysql) or the column is nullable (others)
+	PrimaryKey    bool   `json:"-"`
+	AutoIncrement bool   `json:"-"`
+	Unique        bool   `json:"-"`
+	Default       string `json:"-"`
+	Comment       string `json:"-"`
+}
+
+// Index .
+type Index struct {
+	Name     string   `json:"-"`
+	Unique   bool     `json:"-"`
+	Fulltext bool     `json:"-"`
+	Columns  []string `json:"-"`
+}
+
+// ForeignKey .
+type ForeignKey struct {
+	Name     string   `json:"-"`
+	Columns  []string `json:"-"`
+	RefTable string   `json:"-"`
+	// RefColumns are empty when the primary key of RefTable is referenced implicitly (sqlite)
+	RefColumns []string `json:"-"`
+}
+
+// ColumnIndexes returns the names of the indexes containing column name.
+func (t *Table) ColumnIndexes(name string) []string {
+	var names []string
+	for _, index := range t.Indexes {
+		for _, col := range index.Columns {
+			if strings.EqualFold(col, name) {
+				names = append(names, index.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// gormIndexTags returns the gorm index settings of column name, e.g. index:idx_a_b,priority:2.
+// The priority is only written for composite indexes, it is the position of the column in the index.
+func (t *Table) gormIndexTags(name string) []string {
+	var tags []string
+	for _, index := range t.Indexes {
+		for i, col := range index.Columns {
+			if !strings.EqualFold(col, name) {
+				continue
+			}
+			tag := "index:" + index.Name
+			if index.Unique {
+				tag = "uniqueIndex:" + index.Name
+			}
+			if len(index.Columns) > 1 {
+				tag += fmt.Sprintf(",priority:%d", i+1)
+			}
+			if index.Fulltext {
+				tag += ",class:FULLTEXT"
+			}
+			tags = append(tags, tag)
+			break
+		}
+	}
+	return tags
+}
+
+// index returns the position of index name, -1 if not exists.
+func (t *Table) index(name string) int {
+	for i, index := range t.Indexes {
+		if strings.EqualFold(index.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// addIndex .
+func (t *Table) addIndex(index *Index) error {
+	if t.index(index.Name) >= 0 {
+		return errors.Errorf("index(%s) already exists", index.Name)
+	}
+	for _, col := range index.Columns {
+		if t.column(col) < 0 {
+			return errors.Errorf("index(%s): column(%s) not found", index.Name, col)
+		}
+	}
+	t.Indexes = append(t.Indexes, index)
+	return nil
+}
+
+// dropIndex .
+func (t *Table) dropIndex(name string) error {
+	i := t.index(name)
+	if i < 0 {
+		return errors.Errorf("index(%s) not found", name)
+	}
+	t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+	return nil
+}
+
+// dropForeignKey .
+func (t *Table) dropForeignKey(name string) error {
+	for i, fk := range t.ForeignKeys {
+		if strings.EqualFold(fk.Name, name) {
+			t.ForeignKeys = append(t.ForeignKeys[:i], t.ForeignKeys[i+1:]...)
+			return nil
+		}
+	}
+	return errors.Errorf("foreign key(%s) not found", name)
+}
+
+// renameIndexColumn follows a renamed or dropped column (newName is empty) in the indexes and foreign keys,
+// a foreign key loses all its columns when one of them is dropped.
+func (t *Table) renameIndexColumn(oldName, newName string) {
+	fks := t.ForeignKeys[:0]
+	for _, fk := range t.ForeignKeys {
+		keep := true
+		for i, col := range fk.Columns {
+			if strings.EqualFold(col, oldName) {
+				fk.Columns[i] = newName
+				keep = newName != ""
+			}
+		}
+		if keep {
+			fks = append(fks, fk)
+		}
+	}
+	t.ForeignKeys = fks
+
+	indexes := t.Indexes[:0]
+	for _, index := range t.Indexes {
+		cols := index.Columns[:0]
+		for _, col := range index.Columns {
+			switch {
+			case !strings.EqualFold(col, oldName):
+				cols = append(cols, col)
+			case newName != "":
+				cols = append(cols, newName)
+			}
+		}
+		index.Columns = cols
+		if len(cols) > 0 {
+			indexes = append(indexes, index)
+		}
+	}
+	t.Indexes = indexes
+}
+
+// indexFromConstraint returns nil for constraints which are not secondary indexes.
+func indexFromConstraint(con *ast.Constraint) *Index {
+	index := &Index{Name: con.Name}
+	switch con.Tp {
+	case ast.ConstraintKey, ast.ConstraintIndex:
+	case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+		index.Unique = true
+	case ast.ConstraintFulltext:
+		index.Fulltext = true
+	default:
+		return nil
+	}
+	for _, key := range con.Keys {
+		index.Columns = append(index.Columns, key.Column.Name.String())
+	}
+	// mysql names an anonymous index after its first column
+	if index.Name == "" && len(index.Columns) > 0 {
+		index.Name = index.Columns[0]
+	}
+	return index
+}
+
+// foreignKeyFromConstraint returns nil for constraints which are not foreign keys.
+func foreignKeyFromConstraint(con *ast.Constraint) *ForeignKey {
+	if con.Tp != ast.ConstraintForeignKey || con.Refer == nil {
+		return nil
+	}
+	fk := &ForeignKey{Name: con.Name, RefTable: con.Refer.Table.Name.String()}
+	for _, key := range con.Keys {
+		fk.Columns = append(fk.Columns, key.Column.Name.String())
+	}
+	for _, key := range con.Refer.IndexColNames {
+		fk.RefColumns = append(fk.RefColumns, key.Column.Name.String())
+	}
+	if fk.Name == "" {
+		fk.Name = fk.Columns[0]
+	}
+	return fk
+}
+
+// foreignKeyFromColumn returns the foreign key of an inline REFERENCES clause, nil if there is none.
+func foreignKeyFromColumn(def *ast.ColumnDef) *ForeignKey {
+	for _, o := range def.Options {
+		if o.Tp == ast.ColumnOptionReference && o.Refer != nil {
+			fk := &ForeignKey{
+				Name:     def.Name.Name.String(),
+				Columns:  []string{def.Name.Name.String()},
+				RefTable: o.Refer.Table.Name.String(),
+			}
+			for _, key := range o.Refer.IndexColNames {
+				fk.RefColumns = append(fk.RefColumns, key.Column.Name.String())
+			}
+			return fk
+		}
+	}
+	return nil
+}
+
+// column returns the index of column name, -1 if not exists.
+func (t *Table) column(name string) int {
+	for i, col := range t.Columns {
+		if strings.EqualFold(col.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertColumn inserts col at position, appends it if there is no position.
+func (t *Table) insertColumn(col *Column, position *ast.ColumnPosition) error {
+	i := len(t.Columns)
+	if position != nil {
+		switch position.Tp {
+		case ast.ColumnPositionFirst:
+			i = 0
+		case ast.ColumnPositionAfter:
+			after := t.column(position.RelativeColumn.Name.String())
+			if after < 0 {
+				return errors.Errorf("column(%s) not found", position.RelativeColumn.Name.String())
+			}
+			i = after + 1
+		}
+	}
+	t.Columns = append(t.Columns[:i], append([]*Column{col}, t.Columns[i:]...)...)
+	return nil
+}
+
+// clone .
+func (t *Table) clone() *Table {
+	c := *t
+	c.Columns = make([]*Column, 0, len(t.Columns))
+	for _, col := range t.Columns {
+		cc := *col
+		c.Columns = append(c.Columns, &cc)
+	}
+	c.Indexes = make([]*Index, 0, len(t.Indexes))
+	for _, index := range t.Indexes {
+		ci := *index
+		ci.Columns = append([]string(nil), index.Columns...)
+		c.Indexes = append(c.Indexes, &ci)
+	}
+	c.ForeignKeys = make([]*ForeignKey, 0, len(t.ForeignKeys))
+	for _, fk := range t.ForeignKeys {
+		cf := *fk
+		cf.Columns = append([]string(nil), fk.Columns...)
+		cf.RefColumns = append([]string(nil), fk.RefColumns...)
+		c.ForeignKeys = append(c.ForeignKeys, &cf)
+	}
+	return &c
+}
+
+// tableFromCreateStmt .
+func tableFromCreateStmt(stmt *ast.CreateTableStmt) *Table {
+	table := &Table{
+		Name:    stmt.Table.Name.String(),
+		Driver:  DriverMySQL,
+		Columns: make([]*Column, 0, len(stmt.Cols)),
+	}
+
+	// find table comment
+	for _, opt := range stmt.Options {
+		if opt.Tp == ast.TableOptionComment {
+			table.Comment = opt.StrValue
+			break
+		}
+	}
+
+	isPrimaryKey := make(map[string]bool)
+	for _, con := range stmt.Constraints {
+		if con.Tp == ast.ConstraintPrimaryKey {
+			for _, key := range con.Keys {
+				isPrimaryKey[key.Column.Name.String()] = true
+			}
+		}
+	}
+
+	for _, col := range stmt.Cols {
+		table.Columns = append(table.Columns, columnFromDef(col, isPrimaryKey))
+		if fk := foreignKeyFromColumn(col); fk != nil {
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+	}
+	for _, con := range stmt.Constraints {
+		if index := indexFromConstraint(con); index != nil {
+			table.Indexes = append(table.Indexes, index)
+		}
+		if fk := foreignKeyFromConstraint(con); fk != nil {
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+	}
+	return table
+}
+
+// columnFromDef .
+func columnFromDef(def *ast.ColumnDef, isPrimaryKey map[string]bool) *Column {
+	col := &Column{
+		Name:       def.Name.Name.String(),
+		Tp:         def.Tp,
+		SQLType:    def.Tp.InfoSchemaStr(),
+		PrimaryKey: isPrimaryKey[def.Name.Name.String()],
+	}
+	for _, o := range def.Options {
+		switch o.Tp {
+		case ast.ColumnOptionPrimaryKey:
+			col.PrimaryKey = true
+		case ast.ColumnOptionNotNull:
+			col.NotNull = true
+		case ast.ColumnOptionAutoIncrement:
+			col.AutoIncrement = true
+		case ast.ColumnOptionDefaultValue:
+			col.Default = getDefaultValue(o.Expr)
+		case ast.ColumnOptionUniqKey:
+			col.Unique = true
+		case ast.ColumnOptionNull:
+			col.Nullable = true
+		case ast.ColumnOptionOnUpdate: // For Timestamp and Datetime only.
+		case ast.ColumnOptionFulltext:
+		case ast.ColumnOptionComment:
+			col.Comment = strings.Replace(o.Expr.GetDatum().GetString(), "\n", "", -1)
+		default:
+		}
+	}
+	return col
+}
+
+// GetTablesFromSQL parses sql such as a mysqldump --no-data file and replays its
+// CREATE/ALTER/DROP/RENAME TABLE statements, other statements are skipped.
+func GetTablesFromSQL(sql string, options ...Option) ([]*Table, error) {
+	schema := NewSchema(options...)
+	if err := schema.Apply(sql); err != nil {
+		return nil, err
+	}
+	return schema.Tables(), nil
+}
+
+// parseCreateTable parses the CREATE TABLE statement of tableName in sql.
+func parseCreateTable(sql, tableName string, options ...Option) (*Table, error) {
+	tables, err := GetTablesFromSQL(sql, options...)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, errors.Errorf("table(%s) not found", tableName)
+	}
+	return tables[0], nil
+}
+
+// unquoteDefault strips the quotes of a string literal default value.
+func unquoteDefault(value string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		value = strings.Replace(value[1:len(value)-1], "''", "'", -1)
+	}
+	return value
+}
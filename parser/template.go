@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/jinzhu/inflection"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StructTemplateName is the struct template file looked up by WithTemplateDir, executed with TmplData.
+	StructTemplateName = "struct.tmpl"
+
+	// FileTemplateName is the file template looked up by WithTemplateDir, executed with ModelCodes.
+	FileTemplateName = "file.tmpl"
+)
+
+// tmplFuncs are the helper functions available in both built-in and user templates.
+var tmplFuncs = template.FuncMap{
+	"camel":      toCamel,
+	"lowerCamel": toLowerCamel,
+	"snake":      toSnake,
+	"plural":     inflection.Plural,
+	"singular":   inflection.Singular,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"join":       strings.Join,
+	"contains":   strings.Contains,
+	"hasPrefix":  strings.HasPrefix,
+	"hasSuffix":  strings.HasSuffix,
+	"trimPrefix": strings.TrimPrefix,
+	"trimSuffix": strings.TrimSuffix,
+	"replace":    strings.ReplaceAll,
+}
+
+// loadStructTemplate returns the user struct template if configured, the built-in one otherwise.
+func loadStructTemplate(opt options) (*template.Template, error) {
+	initTemplate()
+	if err := checkTemplateDir(opt.TemplateDir); err != nil {
+		return nil, err
+	}
+	if opt.StructTemplate == "" {
+		return structTmpl, nil
+	}
+	return parseTemplateFile(opt.StructTemplate)
+}
+
+// loadFileTemplate returns the user file template if configured, the built-in one otherwise.
+func loadFileTemplate(opt options) (*template.Template, error) {
+	initTemplate()
+	if err := checkTemplateDir(opt.TemplateDir); err != nil {
+		return nil, err
+	}
+	if opt.FileTemplate == "" {
+		return fileTmpl, nil
+	}
+	return parseTemplateFile(opt.FileTemplate)
+}
+
+// checkTemplateDir fails if the dir of WithTemplateDir is missing or not readable,
+// a mistyped dir must not fall back to the built-in templates silently.
+func checkTemplateDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if _, err := os.ReadDir(dir); err != nil {
+		return errors.WithMessage(err, "read template dir error")
+	}
+	return nil
+}
+
+// parseTemplateFile .
+func parseTemplateFile(path string) (*template.Template, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read template error")
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(tmplFuncs).Parse(string(b))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "parse template %s error", path)
+	}
+	return tmpl, nil
+}
+
+// writeModelCodes renders data with the file template.
+func writeModelCodes(data ModelCodes, writer io.Writer, options ...Option) error {
+	tmpl, err := loadFileTemplate(parseOption(options))
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(writer, data)
+}
+
+// toLowerCamel .
+func toLowerCamel(s string) string {
+	s = toCamel(s)
+	i := 0
+	for i < len(s) && s[i] >= 'A' && s[i] <= 'Z' {
+		i++
+	}
+	// keep the last upper letter of a leading acronym, e.g. IDCard -> idCard
+	if i > 1 && i < len(s) && s[i] >= 'a' && s[i] <= 'z' {
+		i--
+	}
+	return strings.ToLower(s[:i]) + s[i:]
+}
+
+// toSnake .
+func toSnake(s string) string {
+	runes := []rune(strings.TrimSpace(s))
+	b := strings.Builder{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) &&
+				runes[i-1] != '_' {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		if r == '-' || r == ' ' {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// fileExists .
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
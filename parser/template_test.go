@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	cases := []struct {
+		fn, in, want string
+	}{
+		{"lowerCamel", "user_name", "userName"},
+		{"lowerCamel", "id_card", "idCard"},
+		{"lowerCamel", "id", "id"},
+		{"snake", "UserName", "user_name"},
+		{"snake", "IDCard", "id_card"},
+		{"snake", "userID", "user_id"},
+	}
+	for _, c := range cases {
+		got := tmplFuncs[c.fn].(func(string) string)(c.in)
+		if got != c.want {
+			t.Errorf("%s(%q) = %q, want %q", c.fn, c.in, got, c.want)
+		}
+	}
+}
+
+func TestWithTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	structTmpl := `type {{.TableName}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} ` + "`db:\"{{.ColumnName}}\"`" + ` // {{.SQLType}}{{if .PrimaryKey}} pk{{end}}{{if .Nullable}} null{{end}}{{range .Indexes}} {{.}}{{end}}
+{{- end}}
+}
+
+// {{plural (lowerCamel .TableName)}} in package {{.Package}}
+`
+	fileTmpl := "// Code generated by gmodel. DO NOT EDIT.\n\npackage {{.Package}}\n{{range .StructCode}}\n{{.}}{{end}}"
+	if err := os.WriteFile(filepath.Join(dir, StructTemplateName), []byte(structTmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileTemplateName), []byte(fileTmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sql := "CREATE TABLE `user_account` (`id` int NOT NULL, `email` varchar(64), PRIMARY KEY (`id`), KEY `idx_email` (`email`));"
+	buf := &bytes.Buffer{}
+	if err := ParseSQLToWrite(sql, buf, WithTemplateDir(dir), WithPackage("dao")); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"// Code generated by gmodel. DO NOT EDIT.",
+		"package dao",
+		"ID    int    `db:\"id\"`    // int(11) pk",
+		"Email string `db:\"email\"` // varchar(64) null idx_email",
+		"// userAccounts in package dao",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("%q not found in:\n%s", s, out)
+		}
+	}
+
+	// an empty dir keeps the built-in templates
+	buf.Reset()
+	if err := ParseSQLToWrite(sql, buf, WithTemplateDir(t.TempDir())); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `gorm:"column:id;primaryKey"`) {
+		t.Errorf("built-in template expected:\n%s", buf.String())
+	}
+
+	if err := ParseSQLToWrite(sql, buf, WithTemplateFile(filepath.Join(dir, "missing.tmpl"), "")); err == nil {
+		t.Error("missing template should fail")
+	}
+	if err := ParseSQLToWrite(sql, buf, WithTemplateDir(filepath.Join(dir, "missing"))); err == nil {
+		t.Error("missing template dir should fail")
+	}
+}
@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+var (
+	tsTmplRaw   string
+	tsTmpl      *template.Template
+	tsParseOnce sync.Once
+)
+
+// tsTypes are the TypeScript types of the go field types, as encoding/json writes them.
+var tsTypes = map[string]string{
+	"int":             "number",
+	"int32":           "number",
+	"int64":           "number",
+	"uint":            "number",
+	"uint32":          "number",
+	"uint64":          "number",
+	"float32":         "number",
+	"float64":         "number",
+	"bool":            "boolean",
+	"string":          "string",
+	"[]byte":          "string", // base64
+	"decimal.Decimal": "string",
+	"time.Time":       "string", // RFC 3339
+	"pq.Int64Array":   "number[]",
+	"pq.Float64Array": "number[]",
+	"pq.BoolArray":    "boolean[]",
+	"pq.ByteaArray":   "string[]",
+	"pq.StringArray":  "string[]",
+}
+
+var tsIdentRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsData .
+type tsData struct {
+	Name     string    `json:"-"`
+	Comment  string    `json:"-"`
+	Fields   []tsField `json:"-"`
+	Enums    []tsEnum  `json:"-"`
+	Readonly bool      `json:"-"` // the table is a view
+}
+
+// tsField .
+type tsField struct {
+	Name    string `json:"-"` // property name, quoted if it's not an identifier
+	Type    string `json:"-"`
+	Comment string `json:"-"`
+}
+
+// tsEnum is the string literal union of an enum column, or of the elements of a set column.
+type tsEnum struct {
+	Name    string `json:"-"`
+	Column  string `json:"-"`
+	Set     bool   `json:"-"`
+	Literal string `json:"-"` // e.g. 'new' | 'paid'
+}
+
+// MakeTypeScript renders the TypeScript interface of the json the go struct of table is encoded to:
+// the properties are the column names with WithJSONTag, the go field names without, and the types follow
+// the field types of the null style: a pointer is T | null, a sql.NullXXX the { String: string; Valid: boolean }
+// object it is encoded to. Enum columns are string literal unions; int64 columns are number, or string
+// with WithTSBigIntString which adds the ,string option to their json tags.
+func MakeTypeScript(table *Table, options ...Option) (string, error) {
+	opt := parseOption(options)
+	tsParseOnce.Do(func() {
+		tsTmpl = template.Must(template.New("goTypeScript").Parse(tsTmplRaw))
+	})
+
+	opt.Associations = false
+	model, _ := makeTmplData(table, opt)
+
+	data := tsData{
+		Name:     model.TableName,
+		Comment:  strings.Join(strings.Fields(table.Comment), " "),
+		Readonly: table.View,
+	}
+	enums := make(map[string]TmplEnum, len(model.Enums))
+	for _, enum := range model.Enums {
+		enums[enum.Column] = enum
+		literals := make([]string, 0, len(enum.Values))
+		for _, v := range enum.Values {
+			literals = append(literals, tsString(v.Value))
+		}
+		data.Enums = append(data.Enums, tsEnum{
+			Name:    enum.Name,
+			Column:  enum.Column,
+			Set:     enum.Set,
+			Literal: strings.Join(literals, " | "),
+		})
+	}
+
+	for i, f := range model.Fields {
+		field := tsField{Name: f.Name, Comment: strings.Join(strings.Fields(f.Comment), " ")}
+		if opt.JSONTag {
+			field.Name = f.ColumnName
+		}
+		if !tsIdentRe.MatchString(field.Name) {
+			field.Name = tsString(field.Name)
+		}
+		var enum *TmplEnum
+		if e, ok := enums[f.ColumnName]; ok {
+			enum = &e
+		}
+		field.Type = tsFieldType(f.GoType, enum, table.Columns[i].Nullable, opt)
+		data.Fields = append(data.Fields, field)
+	}
+
+	builder := strings.Builder{}
+	if err := tsTmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// ParseTypeScriptToWrite .
+func ParseTypeScriptToWrite(table *Table, writer io.Writer, options ...Option) error {
+	code, err := MakeTypeScript(table, options...)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, code)
+	return err
+}
+
+// tsFieldType is the TypeScript type of the json of a go field type: a pointer is T | null and a nil slice
+// (bytes, sets and arrays of a nullable column) null, a sql.NullXXX is the object of its value and Valid.
+// The types of the type mapping are unknown, their json is not known.
+func tsFieldType(goType string, enum *TmplEnum, nullable bool, opt options) string {
+	if null, ok := sqlNullTypes[goType]; ok {
+		value := tsTypes[null[1]]
+		if enum != nil {
+			value = enum.Name
+		}
+		return "{ " + null[0] + ": " + value + "; Valid: boolean }"
+	}
+	ptr := strings.HasPrefix(goType, "*")
+	goType = strings.TrimPrefix(goType, "*")
+
+	var typ string
+	switch {
+	case enum != nil && enum.Set:
+		typ = enum.Name + "[]"
+	case enum != nil:
+		typ = enum.Name
+	case opt.TSBigIntString && (goType == "int64" || goType == "uint64"):
+		typ = "string"
+	default:
+		var ok bool
+		if typ, ok = tsTypes[goType]; !ok {
+			return "unknown"
+		}
+	}
+	if ptr || (nullable && strings.HasSuffix(typ, "[]")) || (nullable && goType == "[]byte") {
+		typ += " | null"
+	}
+	return typ
+}
+
+// tsString quotes s with single quotes.
+func tsString(s string) string {
+	quoted := strconv.Quote(s)
+	quoted = strings.ReplaceAll(quoted[1:len(quoted)-1], `\"`, `"`)
+	return "'" + strings.ReplaceAll(quoted, "'", `\'`) + "'"
+}
+
+func init() {
+	tsTmplRaw = `// Code generated by gmodel.
+{{if .Comment}}
+/** {{.Comment}} */
+{{- end}}
+export interface {{.Name}} {
+{{- range .Fields}}
+{{- if .Comment}}
+  /** {{.Comment}} */
+{{- end}}
+  {{if $.Readonly}}readonly {{end}}{{.Name}}: {{.Type}};
+{{- end}}
+}
+{{- range .Enums}}
+
+/** {{.Name}} is {{if .Set}}an element of set column {{.Column}}{{else}}the value of enum column {{.Column}}{{end}}. */
+export type {{.Name}} = {{.Literal}};
+{{- end}}
+`
+}
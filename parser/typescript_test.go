@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMakeTypeScript(t *testing.T) {
+	tables, err := GetTablesFromSQL("CREATE TABLE `orders` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `status` enum('new','paid') NULL COMMENT 'order status',\n" +
+		"  `tags` set('gift','urgent') NOT NULL,\n" +
+		"  `note` varchar(255) NULL,\n" +
+		"  `amount` decimal(10,2) NOT NULL,\n" +
+		"  `paid_at` datetime NULL,\n" +
+		"  `user-agent` varchar(64) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") COMMENT='订单';")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err := MakeTypeScript(tables[0], WithJSONTag(), WithNullStyle(NullInPointer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `// Code generated by gmodel.
+
+/** 订单 */
+export interface Orders {
+  id: number;
+  /** order status */
+  status: OrderStatus | null;
+  tags: OrderTags[];
+  note: string | null;
+  amount: string;
+  paid_at: string | null;
+  'user-agent': string;
+}
+
+/** OrderStatus is the value of enum column status. */
+export type OrderStatus = 'new' | 'paid';
+
+/** OrderTags is an element of set column tags. */
+export type OrderTags = 'gift' | 'urgent';
+`
+	if ts != want {
+		t.Errorf("MakeTypeScript() =\n%s\nwant\n%s", ts, want)
+	}
+
+	// without json tags encoding/json writes the go field names, sql.NullXXX as objects
+	ts, err = MakeTypeScript(tables[0], WithTSBigIntString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"  ID: string;\n",
+		"  Status: { String: OrderStatus; Valid: boolean };\n",
+		"  PaIDAt: { Time: string; Valid: boolean };\n",
+	} {
+		if !strings.Contains(ts, s) {
+			t.Errorf("interface should contain %q:\n%s", s, ts)
+		}
+	}
+
+	// the models write the int64 of the string interfaces with the ,string option
+	for _, c := range []struct {
+		options []Option
+		want    string
+	}{
+		{[]Option{WithTSBigIntString()}, "ID int64 `json:\",string\" gorm:"},
+		{[]Option{WithTSBigIntString(), WithJSONTag()}, "ID int64 `json:\"id,string\" gorm:"},
+		{[]Option{WithJSONTag()}, "ID int64 `json:\"id\" gorm:"},
+	} {
+		codes, err := ParseTables(tables, c.options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code := strings.Join(strings.Fields(codes.StructCode[0]), " "); !strings.Contains(code, c.want) {
+			t.Errorf("model should contain %q:\n%s", c.want, codes.StructCode[0])
+		}
+	}
+}